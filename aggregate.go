@@ -0,0 +1,87 @@
+package smsc
+
+import "fmt"
+
+// AggregateResult is a result of bulk sends, e.g. SendMany and SendChunked.
+type AggregateResult struct {
+	TotalCount int   // of SMS sent
+	TotalCost  Money // of successful and partly sent sends
+
+	// FailedPhones are phones of failed sends and phones API reported with an
+	// error. Phones which got a part of a partly sent message are not failed,
+	// see PartialError.
+	FailedPhones []string
+
+	// Outcomes are results of separate sends.
+	Outcomes []SendOutcome
+}
+
+// aggregate returns an AggregateResult of outcomes.
+func aggregate(outcomes []SendOutcome) *AggregateResult {
+	a := &AggregateResult{Outcomes: outcomes}
+	for _, o := range outcomes {
+		if o.Err != nil {
+			a.FailedPhones = append(a.FailedPhones, unsentPhones(o.Err, o.Message.Phones)...)
+		}
+		if o.Result == nil {
+			continue
+		}
+		a.TotalCount += o.Result.Count
+		if cost, err := o.Result.CostMoney(); err == nil {
+			a.TotalCost += cost
+		}
+		for _, p := range o.Result.Phones {
+			if p.Error != nil {
+				a.FailedPhones = append(a.FailedPhones, p.Phone)
+			}
+		}
+	}
+	return a
+}
+
+// Failed returns a number of failed sends.
+func (a *AggregateResult) Failed() int {
+	var n int
+	for _, o := range a.Outcomes {
+		if o.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns an *AggregateError if any send failed.
+func (a *AggregateResult) Err() error {
+	var first error
+	for _, o := range a.Outcomes {
+		if o.Err != nil {
+			first = o.Err
+			break
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return &AggregateError{Failed: a.Failed(), Total: len(a.Outcomes), First: first}
+}
+
+// Summary returns a short human readable summary, e.g.
+// "3 of 4 sends succeeded, 6 SMS, cost 9.00, 2 failed phones".
+func (a *AggregateResult) Summary() string {
+	return fmt.Sprintf("%d of %d sends succeeded, %d SMS, cost %s, %d failed phones",
+		len(a.Outcomes)-a.Failed(), len(a.Outcomes), a.TotalCount, a.TotalCost, len(a.FailedPhones))
+}
+
+// AggregateError reports failed sends of an AggregateResult.
+type AggregateError struct {
+	Failed, Total int
+	First         error // of failed sends
+}
+
+func (e *AggregateError) Error() string {
+	return fmt.Sprintf("smsc: %d of %d sends failed: %v", e.Failed, e.Total, e.First)
+}
+
+func (e *AggregateError) Unwrap() error {
+	return e.First
+}
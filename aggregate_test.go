@@ -0,0 +1,80 @@
+package smsc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	cost := "1.50"
+	failed := "invalid number"
+	errAuth := &Error{Code: CodeAuth, Desc: "authorise error"}
+
+	a := aggregate([]SendOutcome{
+		{
+			Message: OutgoingMessage{Phones: []string{"1", "2"}},
+			Result: &Result{Count: 1, Cost: &cost, Phones: []Phone{
+				{Phone: "1"},
+				{Phone: "2", Error: &failed},
+			}},
+		},
+		{
+			Message: OutgoingMessage{Phones: []string{"3"}},
+			Result:  &Result{Count: 2, Cost: &cost},
+		},
+		{
+			Message: OutgoingMessage{Phones: []string{"4", "5"}},
+			Err:     errAuth,
+		},
+	})
+
+	if a.TotalCount != 3 {
+		t.Errorf("count: want 3, got %d", a.TotalCount)
+	}
+	if a.TotalCost != 300 {
+		t.Errorf("cost: want 3.00, got %s", a.TotalCost)
+	}
+	if want := []string{"2", "4", "5"}; !reflect.DeepEqual(want, a.FailedPhones) {
+		t.Errorf("failed phones: want %v, got %v", want, a.FailedPhones)
+	}
+
+	err := a.Err()
+	want := &AggregateError{Failed: 1, Total: 3, First: errAuth}
+	if !reflect.DeepEqual(want, err) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+	if !errors.Is(err, errAuth) {
+		t.Error("error does not wrap the first failure")
+	}
+
+	s := "2 of 3 sends succeeded, 3 SMS, cost 3.00, 3 failed phones"
+	if a.Summary() != s {
+		t.Errorf("summary: want %q, got %q", s, a.Summary())
+	}
+}
+
+func TestAggregate_ok(t *testing.T) {
+	a := aggregate([]SendOutcome{{Result: &Result{Count: 1}}})
+	if err := a.Err(); err != nil {
+		t.Errorf("want nil, got %v", err)
+	}
+}
+
+func TestAggregate_partial(t *testing.T) {
+	cost := "1.50"
+	a := aggregate([]SendOutcome{{
+		Message: OutgoingMessage{Phones: []string{"1", "2", "3"}},
+		Result:  &Result{Count: 2, Cost: &cost},
+		Err:     &PartialError{Sent: 1, Total: 2, Unsent: []string{"3"}, Err: ErrEmptyResult},
+	}})
+	if a.TotalCount != 2 || a.TotalCost != 150 {
+		t.Errorf("want 2 SMS of 1.50, got %d of %s", a.TotalCount, a.TotalCost)
+	}
+	if want := []string{"3"}; !reflect.DeepEqual(want, a.FailedPhones) {
+		t.Errorf("failed phones: want %v, got %v", want, a.FailedPhones)
+	}
+	if a.Failed() != 1 {
+		t.Errorf("want 1 failed send, got %d", a.Failed())
+	}
+}
@@ -0,0 +1,87 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+var ErrLowBalance = errors.New("smsc: balance is below minimum")
+
+// Balance returns the account balance.
+func (c *Client) Balance(ctx context.Context) (Money, error) {
+	var r struct {
+		Balance Money `json:"balance"`
+	}
+	if err := c.call(ctx, "balance.php", url.Values{}, &r); err != nil {
+		return 0, err
+	}
+	return r.Balance, nil
+}
+
+// Cost returns a price of text sent to phones with opts. API computes it with
+// cost=1, so nothing is sent and the balance is not changed. See Preview for
+// prices of each phone.
+func (c *Client) Cost(ctx context.Context, text string, phones []string, opts ...Opt) (Money, error) {
+	opts = append(opts[:len(opts):len(opts)], With(CostWithoutSend))
+	r, err := c.SendContext(ctx, text, phones, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return r.CostMoney()
+}
+
+// projectionPeriod is how far after now queued messages are looked up by
+// ProjectedBalance. Scheduled messages are listed by their send time.
+const projectionPeriod = 30 * 24 * time.Hour
+
+// ProjectedBalance returns the account balance and a balance after all queued
+// messages are charged, e.g. ones scheduled with WithSendTime. Both are Money,
+// as Balance returns. Queued messages are found in the history of send times
+// from today to a month later, so it costs a request per 1000 messages of the
+// period and messages sent before today are not listed.
+func (c *Client) ProjectedBalance(ctx context.Context) (current, projected Money, err error) {
+	current, err = c.Balance(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	now := c.now()
+	projected = current
+	it := c.HistoryIterator(ctx, now, now.Add(projectionPeriod))
+	for it.Next() {
+		if e := it.Entry(); e.Status == StatusQueued {
+			projected -= e.Cost
+		}
+	}
+	if err := it.Err(); err != nil {
+		return 0, 0, err
+	}
+	return current, projected, nil
+}
+
+// checkBalance returns ErrLowBalance when the balance is below
+// Config.MinBalance. Cost requests are not checked.
+func (c *Client) checkBalance(ctx context.Context, m *message) error {
+	if c.minBalance <= 0 || m.Cost == CostWithoutSend {
+		return nil
+	}
+	b, err := c.cachedBalance(ctx)
+	if err != nil {
+		return err
+	}
+	if b < c.minBalance {
+		return ErrLowBalance
+	}
+	return nil
+}
+
+// updateBalance caches a balance of r if API returned it.
+func (c *Client) updateBalance(r *Result) {
+	if c.minBalance <= 0 || r.Balance == nil {
+		return
+	}
+	if b, err := parseMoney(*r.Balance); err == nil {
+		c.setBalance(b)
+	}
+}
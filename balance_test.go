@@ -0,0 +1,198 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Balance(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/balance.php" {
+			t.Errorf("path: want %q, got %q", "/balance.php", r.URL.Path)
+		}
+		w.Write([]byte(`{"balance": "100.50"}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.Balance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != 10050 {
+		t.Errorf("want 100.50, got %s", b)
+	}
+}
+
+func TestClient_Send_minBalance(t *testing.T) {
+	balance := "5.00"
+	var balanceRequests, sends int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/balance.php" {
+			balanceRequests++
+			w.Write([]byte(`{"balance": "` + balance + `"}`))
+			return
+		}
+		sends++
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass", MinBalance: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := day
+	c.now = func() time.Time { return now }
+
+	if _, err := c.Send("test", somePhone); err != ErrLowBalance {
+		t.Errorf("want %v, got %v", ErrLowBalance, err)
+	}
+	balance = "50.00"
+	if _, err := c.Send("test", somePhone); err != ErrLowBalance {
+		t.Errorf("cached: want %v, got %v", ErrLowBalance, err)
+	}
+	if _, err := c.Send("test", somePhone, With(CostWithoutSend)); err != nil {
+		t.Errorf("cost: %v", err)
+	}
+
+	now = now.Add(DefaultBalanceTTL)
+	if _, err := c.Send("test", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	if balanceRequests != 2 || sends != 2 {
+		t.Errorf("want 2 balance requests and 2 sends, got %d and %d", balanceRequests, sends)
+	}
+}
+
+func TestClient_ProjectedBalance(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/balance.php":
+			w.Write([]byte(`{"balance": "100.00"}`))
+		case "/get.php":
+			if s := r.PostFormValue("start"); s != "01.01.2020" {
+				t.Errorf("start: want %q, got %q", "01.01.2020", s)
+			}
+			if s := r.PostFormValue("end"); s != "31.01.2020" {
+				t.Errorf("end: want %q, got %q", "31.01.2020", s)
+			}
+			w.Write([]byte(`[
+				{"id": 1, "status": -1, "cost": "1.50"},
+				{"id": 2, "status": 1, "cost": "2.00"},
+				{"id": 3, "status": -1, "cost": "3,25"}
+			]`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return day }
+
+	current, projected, err := c.ProjectedBalance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != 10000 || projected != 9525 {
+		t.Errorf("want 100.00 and 95.25, got %s and %s", current, projected)
+	}
+}
+
+func TestClient_Cost(t *testing.T) {
+	var cost string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cost = r.PostFormValue("cost")
+		w.Write([]byte(`{"cost": "3.50", "cnt": 2}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := c.Cost(context.Background(), "test", []string{"1", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != "1" {
+		t.Errorf("cost: want 1, got %q", cost)
+	}
+	if m != 350 {
+		t.Errorf("want 3.50, got %s", m)
+	}
+}
+
+var CostRequestsTests = []struct {
+	Name     string
+	Config   Config
+	Text     string
+	Phones   []string
+	Opts     []Opt
+	Requests int
+}{
+	{"split", Config{}, Generate(abc+" ", 2*smsLatinChars), somePhone, []Opt{WithAutoSplit(smsLatinChars)}, 3},
+	{"local window", Config{}, "test", []string{"+79161234567", "+77011234567"}, []Opt{WithLocalDeliveryWindow(9, 21)}, 2},
+	{"concat", Config{}, strings.Repeat("ab", binSize+1), somePhone, []Opt{With(BinHex), WithConcatRef(ref)}, 2},
+	{"routing", Config{LeastCostRouting: true}, "test", []string{"+79161234567", "+79031234567"}, nil, 2},
+	{"hlr", Config{HLRPrecheck: true}, "test", somePhone, nil, 1},
+}
+
+func TestClient_Cost_requests(t *testing.T) {
+	for _, tt := range CostRequestsTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var sends int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/tariffs.php":
+					json.NewEncoder(w).Encode([]Tariff{{Operator: "MTS", Price: 150, Sender: "Cheap"}})
+				case "/info.php":
+					if tt.Config.HLRPrecheck {
+						t.Error("phone is looked up")
+					}
+					if r.PostFormValue("phone") == "+79161234567" {
+						json.NewEncoder(w).Encode(PhoneInfo{Operator: "MTS"})
+					} else {
+						json.NewEncoder(w).Encode(PhoneInfo{})
+					}
+				default:
+					if s := r.PostFormValue("cost"); s != "1" {
+						t.Errorf("cost: want 1, got %q", s)
+					}
+					sends++
+					w.Write([]byte(`{"cost": "1.50", "cnt": 1}`))
+				}
+			}))
+			defer ts.Close()
+
+			cfg := tt.Config
+			cfg.URL, cfg.Login, cfg.Password = ts.URL+"/send.php", "test", "pass"
+			c, err := New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := c.Cost(context.Background(), tt.Text, tt.Phones, tt.Opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sends != tt.Requests {
+				t.Errorf("want %d requests, got %d", tt.Requests, sends)
+			}
+			if want := Money(150 * tt.Requests); m != want {
+				t.Errorf("want %s, got %s", want, m)
+			}
+		})
+	}
+}
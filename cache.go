@@ -0,0 +1,184 @@
+package smsc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Default TTLs of cached account data.
+const (
+	DefaultTariffsTTL = 24 * time.Hour
+	DefaultSendersTTL = 24 * time.Hour
+	DefaultBalanceTTL = time.Minute
+)
+
+// accountCache keeps account data used to check and route messages. Data of
+// each kind is fetched on the first use and again when it is older than its
+// TTL.
+//
+// mu guards data and is not held during requests. A fetch lock of each kind
+// lets one caller fetch it while others wait for the result, and fresh data
+// of other kinds is read meanwhile.
+type accountCache struct {
+	mu sync.Mutex
+
+	tariffs   []Tariff
+	tariffsAt time.Time
+	senders   map[string]bool
+	sendersAt time.Time
+	balance   Money
+	balanceAt time.Time
+
+	tariffsFetch sync.Mutex
+	sendersFetch sync.Mutex
+	balanceFetch sync.Mutex
+}
+
+// ttls are lifetimes of accountCache data.
+type ttls struct {
+	Tariffs, Senders, Balance time.Duration
+}
+
+// fresh tells whether data fetched at is younger than ttl at now.
+func fresh(at, now time.Time, ttl time.Duration) bool {
+	return !at.IsZero() && now.Sub(at) < ttl
+}
+
+// cachedTariffs returns Tariffs of the account.
+func (c *Client) cachedTariffs(ctx context.Context) ([]Tariff, error) {
+	if tt, ok := c.freshTariffs(); ok {
+		return tt, nil
+	}
+	c.cache.tariffsFetch.Lock()
+	defer c.cache.tariffsFetch.Unlock()
+	if tt, ok := c.freshTariffs(); ok { // fetched while waiting
+		return tt, nil
+	}
+	tt, err := c.Tariffs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.mu.Lock()
+	c.cache.tariffs, c.cache.tariffsAt = tt, c.now()
+	c.cache.mu.Unlock()
+	return tt, nil
+}
+
+// freshTariffs returns cached Tariffs unless they are expired.
+func (c *Client) freshTariffs() ([]Tariff, bool) {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	return c.cache.tariffs, fresh(c.cache.tariffsAt, c.now(), c.ttls.Tariffs)
+}
+
+// cachedSenders returns a set of approved senders of the account.
+func (c *Client) cachedSenders(ctx context.Context) (map[string]bool, error) {
+	if set, ok := c.freshSenders(); ok {
+		return set, nil
+	}
+	c.cache.sendersFetch.Lock()
+	defer c.cache.sendersFetch.Unlock()
+	if set, ok := c.freshSenders(); ok {
+		return set, nil
+	}
+	names, err := c.Senders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	set := senderSet(names)
+	c.cache.mu.Lock()
+	c.cache.senders, c.cache.sendersAt = set, c.now()
+	c.cache.mu.Unlock()
+	return set, nil
+}
+
+// freshSenders returns cached senders unless they are expired.
+func (c *Client) freshSenders() (map[string]bool, bool) {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	return c.cache.senders, fresh(c.cache.sendersAt, c.now(), c.ttls.Senders)
+}
+
+// cachedBalance returns the account balance.
+func (c *Client) cachedBalance(ctx context.Context) (Money, error) {
+	if b, ok := c.freshBalance(); ok {
+		return b, nil
+	}
+	c.cache.balanceFetch.Lock()
+	defer c.cache.balanceFetch.Unlock()
+	if b, ok := c.freshBalance(); ok {
+		return b, nil
+	}
+	b, err := c.Balance(ctx)
+	if err != nil {
+		return 0, err
+	}
+	c.setBalance(b)
+	return b, nil
+}
+
+// freshBalance returns the cached balance unless it is expired.
+func (c *Client) freshBalance() (Money, bool) {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	return c.cache.balance, fresh(c.cache.balanceAt, c.now(), c.ttls.Balance)
+}
+
+// setBalance caches b, e.g. of Result.Balance.
+func (c *Client) setBalance(b Money) {
+	c.cache.mu.Lock()
+	c.cache.balance, c.cache.balanceAt = b, c.now()
+	c.cache.mu.Unlock()
+}
+
+// Refresh fetches cached account data again, e.g. after account settings
+// are changed. Only data of enabled features is fetched: tariffs of
+// Config.LeastCostRouting, senders of Config.ValidateSenderLocally and the
+// balance of Config.MinBalance.
+//
+// Caches are replaced together under a lock when all data is fetched, so a
+// send never sees data which is only partly refreshed. Caches are not
+// changed on an error.
+func (c *Client) Refresh(ctx context.Context) error {
+	var (
+		tariffs []Tariff
+		senders map[string]bool
+		balance Money
+	)
+	if c.lcr {
+		tt, err := c.Tariffs(ctx)
+		if err != nil {
+			return err
+		}
+		tariffs = tt
+	}
+	if c.validateSender {
+		names, err := c.Senders(ctx)
+		if err != nil {
+			return err
+		}
+		senders = senderSet(names)
+	}
+	if c.minBalance > 0 {
+		b, err := c.Balance(ctx)
+		if err != nil {
+			return err
+		}
+		balance = b
+	}
+
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	now := c.now()
+	if c.lcr {
+		c.cache.tariffs, c.cache.tariffsAt = tariffs, now
+	}
+	if c.validateSender {
+		c.cache.senders, c.cache.sendersAt = senders, now
+	}
+	if c.minBalance > 0 {
+		c.cache.balance, c.cache.balanceAt = balance, now
+	}
+	return nil
+}
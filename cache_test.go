@@ -0,0 +1,123 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_Refresh(t *testing.T) {
+	balance := "100.00"
+	requests := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/tariffs.php":
+			json.NewEncoder(w).Encode([]Tariff{{Operator: "MTS", Price: 150}})
+		case "/senders.php":
+			w.Write([]byte(`[{"sender": "Shop"}]`))
+		case "/balance.php":
+			w.Write([]byte(`{"balance": "` + balance + `"}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{
+		URL:                   ts.URL,
+		Login:                 "test",
+		Password:              "pass",
+		LeastCostRouting:      true,
+		ValidateSenderLocally: true,
+		MinBalance:            1000,
+		BalanceTTL:            time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return day }
+	ctx := context.Background()
+
+	if _, err := c.cachedBalance(ctx); err != nil {
+		t.Fatal(err)
+	}
+	balance = "5.00"
+	if b, _ := c.cachedBalance(ctx); b != 10000 {
+		t.Errorf("cached balance: want 100.00, got %s", b)
+	}
+
+	if err := c.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"/tariffs.php": 1, "/senders.php": 1, "/balance.php": 2}
+	if !reflect.DeepEqual(want, requests) {
+		t.Errorf("requests: want %v, got %v", want, requests)
+	}
+	if b, _ := c.cachedBalance(ctx); b != 500 {
+		t.Errorf("refreshed balance: want 5.00, got %s", b)
+	}
+	if err := c.checkSender(ctx, "Shop"); err != nil {
+		t.Error(err)
+	}
+	if _, err := c.cachedTariffs(ctx); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(want, requests) {
+		t.Errorf("requests after refresh: want %v, got %v", want, requests)
+	}
+}
+
+func TestClient_Refresh_error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/balance.php" {
+			w.Write([]byte(`{"error": "authorise error", "error_code": 2}`))
+			return
+		}
+		w.Write([]byte(`[{"sender": "Shop"}]`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", ValidateSenderLocally: true, MinBalance: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Refresh(context.Background()); err == nil {
+		t.Fatal("want error, got nil")
+	}
+	if c.cache.senders != nil {
+		t.Error("senders are cached after a failed refresh")
+	}
+}
+
+func TestClient_cachedBalance_duringFetch(t *testing.T) {
+	fetching, release := make(chan struct{}), make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(fetching)
+		<-release
+		w.Write([]byte(`[{"sender": "Shop"}]`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", ValidateSenderLocally: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := c.cachedSenders(context.Background())
+		done <- err
+	}()
+	<-fetching
+
+	c.setBalance(10000)
+	if b, err := c.cachedBalance(context.Background()); err != nil || b != 10000 {
+		t.Errorf("want 100.00, got %s, %v", b, err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+package smsc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrBadCallback          = errors.New("smsc: invalid callback request")
+	ErrCallbackNotSupported = errors.New("smsc: per-message callbacks are not supported by the account")
+)
+
+// callbackError is an *Error of a message with a callback URL which API
+// rejects with CodeParams because of the callback.
+type callbackError struct {
+	Err *Error
+}
+
+func (e *callbackError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrCallbackNotSupported, e.Err)
+}
+
+func (e *callbackError) Is(target error) bool {
+	return target == ErrCallbackNotSupported
+}
+
+func (e *callbackError) Unwrap() error {
+	return e.Err
+}
+
+// callbackRe matches a description of an API error of a rejected callback,
+// e.g. "callback url is not allowed".
+var callbackRe = regexp.MustCompile(`(?i)callback`)
+
+// unsupportedCallback returns a *callbackError for an *Error with CodeParams
+// of m with a callback URL which mentions a callback and err otherwise.
+// CodeParams is returned for any invalid parameter, e.g. a sender, so its
+// description tells a rejected callback.
+func unsupportedCallback(m *message, err error) error {
+	e, ok := err.(*Error)
+	if !ok || m.CallbackURL == "" || e.Code != CodeParams || !callbackRe.MatchString(e.Desc) {
+		return err
+	}
+	return &callbackError{e}
+}
+
+// Callback is a delivery report which API posts to a URL from WithCallbackURL.
+type Callback struct {
+	ID     int64
+	Phone  string
+	Status int // use ParseStatus to get a state
+	Err    int
+	Time   time.Time
+}
+
+// ParseCallback returns a Callback from a request made by API.
+func ParseCallback(r *http.Request) (*Callback, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, wrapErr(err)
+	}
+
+	cb := &Callback{Phone: r.Form.Get("phone")}
+	if cb.Phone == "" {
+		return nil, ErrBadCallback
+	}
+
+	var err error
+	if cb.ID, err = strconv.ParseInt(r.Form.Get("id"), 10, 64); err != nil {
+		return nil, ErrBadCallback
+	}
+	if cb.Status, err = strconv.Atoi(r.Form.Get("status")); err != nil {
+		return nil, ErrBadCallback
+	}
+	if s := r.Form.Get("err"); s != "" {
+		if cb.Err, err = strconv.Atoi(s); err != nil {
+			return nil, ErrBadCallback
+		}
+	}
+	if s := r.Form.Get("time"); s != "" {
+		ts, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, ErrBadCallback
+		}
+		cb.Time = time.Unix(ts, 0)
+	}
+	return cb, nil
+}
+
+// CallbackHandler returns an http.Handler of delivery reports for a URL of
+// WithCallbackURL. fn is called with each report. Invalid requests are
+// answered with 400 status, so they are not mistaken for accepted reports.
+func CallbackHandler(fn func(*Callback)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cb, err := ParseCallback(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fn(cb)
+		w.Write([]byte("OK"))
+	})
+}
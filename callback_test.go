@@ -0,0 +1,127 @@
+package smsc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var ParseCallbackTests = []struct {
+	Name     string
+	Form     url.Values
+	Callback *Callback
+	Err      error
+}{
+	{
+		"All fields",
+		url.Values{
+			"id":     []string{"100"},
+			"phone":  []string{"71234567890"},
+			"status": []string{"1"},
+			"err":    []string{"0"},
+			"time":   []string{"1577836800"},
+		},
+		&Callback{ID: 100, Phone: "71234567890", Status: 1, Time: time.Unix(1577836800, 0)},
+		nil,
+	},
+	{
+		"Optional fields are omitted",
+		url.Values{
+			"id":     []string{"100"},
+			"phone":  []string{"71234567890"},
+			"status": []string{"-1"},
+		},
+		&Callback{ID: 100, Phone: "71234567890", Status: -1},
+		nil,
+	},
+	{
+		"Phone is required",
+		url.Values{"id": []string{"100"}, "status": []string{"1"}},
+		nil,
+		ErrBadCallback,
+	},
+	{
+		"Bad id",
+		url.Values{"id": []string{"x"}, "phone": []string{"7"}, "status": []string{"1"}},
+		nil,
+		ErrBadCallback,
+	},
+}
+
+func TestParseCallback(t *testing.T) {
+	for _, tt := range ParseCallbackTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.Form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			cb, err := ParseCallback(r)
+			if err != tt.Err {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+			if !reflect.DeepEqual(cb, tt.Callback) {
+				t.Errorf("callback: want %v, got %v", tt.Callback, cb)
+			}
+		})
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	var got []*Callback
+	h := CallbackHandler(func(cb *Callback) { got = append(got, cb) })
+
+	for _, tt := range ParseCallbackTests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(tt.Form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		want := http.StatusOK
+		if tt.Err != nil {
+			want = http.StatusBadRequest
+		}
+		if w.Code != want {
+			t.Errorf("%s: want %d, got %d", tt.Name, want, w.Code)
+		}
+	}
+	if len(got) == 0 || !reflect.DeepEqual(got[0], ParseCallbackTests[0].Callback) {
+		t.Errorf("unexpected callbacks %v", got)
+	}
+}
+
+func TestClient_Send_callbackNotSupported(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("sender") == "Bad" {
+			w.Write([]byte(`{"error": "invalid sender", "error_code": 1}`))
+			return
+		}
+		w.Write([]byte(`{"error": "callback url is not allowed", "error_code": 1}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Send("test", somePhone, WithCallbackURL("https://example.com/cb"))
+	if !errors.Is(err, ErrCallbackNotSupported) {
+		t.Errorf("want %v, got %v", ErrCallbackNotSupported, err)
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodeParams {
+		t.Errorf("want *Error with code %d, got %v", CodeParams, err)
+	}
+
+	// Messages without a callback URL keep an *Error.
+	if _, err := c.Send("test", somePhone); errors.Is(err, ErrCallbackNotSupported) {
+		t.Errorf("unexpected %v", err)
+	}
+	// Other parameter errors keep an *Error.
+	_, err = c.Send("test", somePhone, WithCallbackURL("https://example.com/cb"), Sender("Bad"))
+	if want := (&Error{Code: CodeParams, Desc: "invalid sender"}); !reflect.DeepEqual(want, err) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+}
@@ -0,0 +1,270 @@
+package smsc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Channel defines how a message is delivered.
+type Channel int
+
+// ChannelUnknown is a channel reported by API which the package doesn't know.
+const ChannelUnknown Channel = -1
+
+const (
+	ChannelSMS Channel = iota
+	ChannelViber
+	ChannelMMS
+	ChannelMail
+	ChannelCall
+	ChannelWhatsApp
+)
+
+var channelNames = map[Channel]string{
+	ChannelSMS:      "sms",
+	ChannelViber:    "viber",
+	ChannelMMS:      "mms",
+	ChannelMail:     "mail",
+	ChannelCall:     "call",
+	ChannelWhatsApp: "whatsapp",
+}
+
+func (ch Channel) String() string {
+	if ch == ChannelUnknown {
+		return "unknown"
+	}
+	if s, ok := channelNames[ch]; ok {
+		return s
+	}
+	return fmt.Sprintf("channel(%d)", int(ch))
+}
+
+var ErrBadChannel = errors.New("smsc: unknown channel")
+
+func (ch Channel) MarshalText() ([]byte, error) {
+	s, ok := channelNames[ch]
+	if !ok {
+		return nil, ErrBadChannel
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText parses a channel name. Unknown names are ChannelUnknown, so a
+// response of a message which is sent already doesn't fail.
+func (ch *Channel) UnmarshalText(b []byte) error {
+	for c, s := range channelNames {
+		if s == string(b) {
+			*ch = c
+			return nil
+		}
+	}
+	*ch = ChannelUnknown
+	return nil
+}
+
+var (
+	ErrNoChannels   = errors.New("smsc: empty fallback channels")
+	ErrChannelValid = errors.New("smsc: validity of a channel which is not a fallback channel")
+)
+
+// ChannelSenderError is returned when a fallback channel has no sender in
+// Config.ChannelSenders.
+type ChannelSenderError struct {
+	Channel Channel
+}
+
+func (e *ChannelSenderError) Error() string {
+	return fmt.Sprintf("smsc: no sender registered for %s", e.Channel)
+}
+
+// WithFallbackChannels sends a message to channels in order until one of
+// them delivers it. Result.Channel tells which one did.
+//
+// Each channel waits for the delivery for the period of Valid option, use
+// WithChannelValidity to set a period of a channel. Every channel but SMS
+// requires a sender in Config.ChannelSenders. Kind options, e.g. Viber, cannot
+// be combined with it.
+func WithFallbackChannels(channels ...Channel) Opt {
+	return func(m *message) { m.Channels = channels }
+}
+
+// WithChannelValidity sets how long a fallback channel ch waits for the
+// delivery before the next one is tried. h and m are of Valid.
+func WithChannelValidity(ch Channel, h, m int) Opt {
+	v := newValid(h, m)
+	return func(m *message) {
+		if m.ChannelValid == nil {
+			m.ChannelValid = make(map[Channel]*valid)
+		}
+		m.ChannelValid[ch] = v
+	}
+}
+
+// validateChannels checks m.Channels have senders.
+func (m *message) validateChannels() error {
+	for ch := range m.ChannelValid {
+		if !m.hasChannel(ch) {
+			return ErrChannelValid
+		}
+	}
+	if m.Channels == nil {
+		return nil
+	}
+	if len(m.Channels) == 0 {
+		return ErrNoChannels
+	}
+	for _, ch := range m.Channels {
+		if _, ok := channelNames[ch]; !ok {
+			return ErrBadChannel
+		}
+		if ch == ChannelSMS {
+			continue
+		}
+		if m.ChannelSenders[ch] == "" {
+			return &ChannelSenderError{ch}
+		}
+	}
+	return nil
+}
+
+// hasChannel tells whether ch is one of m.Channels.
+func (m *message) hasChannel(ch Channel) bool {
+	for _, c := range m.Channels {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
+
+// channelsValues returns form values of m.Channels.
+func (m *message) channelsValues() (channels string, senders string) {
+	names := make([]string, len(m.Channels))
+	var pairs []string
+	for i, ch := range m.Channels {
+		names[i] = ch.String()
+		if s := m.ChannelSenders[ch]; s != "" && ch != ChannelSMS {
+			pairs = append(pairs, ch.String()+":"+s)
+		}
+	}
+	return strings.Join(names, ","), strings.Join(pairs, ",")
+}
+
+// channelValidValues returns a form value of m.ChannelValid in order of
+// m.Channels, e.g. "viber:01:00,sms:00:30".
+func (m *message) channelValidValues() string {
+	var pairs []string
+	for _, ch := range m.Channels {
+		if v := m.ChannelValid[ch]; v != nil {
+			pairs = append(pairs, ch.String()+":"+v.String())
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// channelLimits defines maximum text lengths in characters of channels which
+// don't split messages. SMS is limited by smsMaxSize in bytes instead.
+var channelLimits = map[Channel]int{
+	ChannelViber: 1000,
+	ChannelCall:  500,
+}
+
+const (
+	smsLatinChars   = 160
+	smsUnicodeChars = 70
+
+	// Parts of a long SMS are shorter because of a concatenation header.
+	smsLatinPartChars   = 153
+	smsUnicodePartChars = 67
+)
+
+// EstimateParts returns a number of SMS parts text is sent by. API may count
+// differently, e.g. for GSM extension characters.
+func EstimateParts(text string) int {
+	n := utf8.RuneCountInString(text)
+	single, part := smsLatinChars, smsLatinPartChars
+	if !isLatin(text) {
+		single, part = smsUnicodeChars, smsUnicodePartChars
+	}
+	switch {
+	case n == 0:
+		return 0
+	case n <= single:
+		return 1
+	}
+	return (n + part - 1) / part
+}
+
+// LengthError is returned when a text is longer than a channel allows.
+type LengthError struct {
+	Channel Channel
+	Limit   int
+	Length  int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("smsc: %s text length %d exceeds limit %d", e.Channel, e.Length, e.Limit)
+}
+
+// channel returns a Channel of m.
+func (m *message) channel() Channel {
+	switch {
+	case m.Viber != 0:
+		return ChannelViber
+	case m.MMS != 0:
+		return ChannelMMS
+	case m.Mail != 0:
+		return ChannelMail
+	case m.Call != 0:
+		return ChannelCall
+	}
+	return ChannelSMS
+}
+
+// validateLength checks m.Text length against a limit of m channel.
+func (m *message) validateLength() error {
+	ch := m.channel()
+
+	if ch == ChannelSMS {
+		if m.AutoSplit > 0 || m.ConcatRef != nil {
+			// Split messages fit AutoSplit by construction, validateConcat
+			// limits parts of a binary message.
+			return nil
+		}
+		if m.Flash != 0 {
+			// Flash SMS is never split - it must fit a single SMS.
+			n := utf8.RuneCountInString(m.Text)
+			limit := smsLatinChars
+			if !isLatin(m.Text) {
+				limit = smsUnicodeChars
+			}
+			if n > limit {
+				return &LengthError{Channel: ch, Limit: limit, Length: n}
+			}
+			return nil
+		}
+		if n := CountBytes(m.Text); n > smsMaxSize {
+			return ErrLongText
+		}
+		return nil
+	}
+
+	if limit, ok := channelLimits[ch]; ok {
+		if n := utf8.RuneCountInString(m.Text); n > limit {
+			return &LengthError{Channel: ch, Limit: limit, Length: n}
+		}
+	}
+	return nil
+}
+
+// isLatin tells whether s can be sent in 7-bit encoding.
+func isLatin(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,209 @@
+package smsc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+var MessageValidateLengthTests = []struct {
+	Name    string
+	Message message
+	Err     error
+}{
+	{
+		"SMS is split",
+		message{Text: Generate(abc, 700)},
+		nil,
+	},
+	{
+		"Flash SMS in latin fits one SMS",
+		message{Text: Generate(abc, 160), Flash: Flash},
+		nil,
+	},
+	{
+		"Flash SMS in latin is too long",
+		message{Text: Generate(abc, 161), Flash: Flash},
+		&LengthError{Channel: ChannelSMS, Limit: 160, Length: 161},
+	},
+	{
+		"Flash SMS in cyrillic is too long",
+		message{Text: Generate(abcRus, 71), Flash: Flash},
+		&LengthError{Channel: ChannelSMS, Limit: 70, Length: 71},
+	},
+	{
+		"Viber is not limited by SMS size",
+		message{Text: Generate(abcRus, 1000), Viber: Viber},
+		nil,
+	},
+	{
+		"Viber is too long",
+		message{Text: Generate(abcRus, 1001), Viber: Viber},
+		&LengthError{Channel: ChannelViber, Limit: 1000, Length: 1001},
+	},
+	{
+		"Call is too long",
+		message{Text: Generate(abc, 501), Call: Call},
+		&LengthError{Channel: ChannelCall, Limit: 500, Length: 501},
+	},
+	{
+		"Mail is not limited",
+		message{Text: Generate(abc, 5000), Mail: Mail},
+		nil,
+	},
+	{
+		"MMS is not limited",
+		message{Text: Generate(abc, 5000), MMS: MMS},
+		nil,
+	},
+}
+
+func TestMessage_validateLength(t *testing.T) {
+	for _, tt := range MessageValidateLengthTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := tt.Message.validateLength(); !reflect.DeepEqual(err, tt.Err) {
+				t.Errorf("want %v, got %v", tt.Err, err)
+			}
+		})
+	}
+}
+
+var MessageValidateChannelsTests = []struct {
+	Name    string
+	Message message
+	Err     error
+}{
+	{
+		"No fallback",
+		message{},
+		nil,
+	},
+	{
+		"Empty fallback",
+		message{Channels: []Channel{}},
+		ErrNoChannels,
+	},
+	{
+		"SMS needs no sender",
+		message{Channels: []Channel{ChannelSMS}},
+		nil,
+	},
+	{
+		"Senders are registered",
+		message{
+			Channels:       []Channel{ChannelWhatsApp, ChannelViber, ChannelSMS},
+			ChannelSenders: map[Channel]string{ChannelWhatsApp: "Shop", ChannelViber: "ShopViber"},
+		},
+		nil,
+	},
+	{
+		"Sender is not registered",
+		message{
+			Channels:       []Channel{ChannelWhatsApp, ChannelViber, ChannelSMS},
+			ChannelSenders: map[Channel]string{ChannelWhatsApp: "Shop"},
+		},
+		&ChannelSenderError{ChannelViber},
+	},
+	{
+		"Unknown channel",
+		message{Channels: []Channel{Channel(100)}},
+		ErrBadChannel,
+	},
+}
+
+func TestMessage_validateChannels(t *testing.T) {
+	for _, tt := range MessageValidateChannelsTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := tt.Message.validateChannels(); !reflect.DeepEqual(err, tt.Err) {
+				t.Errorf("want %v, got %v", tt.Err, err)
+			}
+		})
+	}
+}
+
+func TestMessage_channelsValues(t *testing.T) {
+	m := message{
+		Channels:       []Channel{ChannelWhatsApp, ChannelViber, ChannelSMS},
+		ChannelSenders: map[Channel]string{ChannelWhatsApp: "Shop", ChannelViber: "ShopViber"},
+	}
+	channels, senders := m.channelsValues()
+	if want := "whatsapp,viber,sms"; channels != want {
+		t.Errorf("channels: want %q, got %q", want, channels)
+	}
+	if want := "whatsapp:Shop,viber:ShopViber"; senders != want {
+		t.Errorf("senders: want %q, got %q", want, senders)
+	}
+}
+
+func TestWithChannelValidity(t *testing.T) {
+	m := message{
+		Text:           "test",
+		Phones:         somePhone,
+		ChannelSenders: map[Channel]string{ChannelViber: "Shop"},
+	}
+	With(WithFallbackChannels(ChannelViber, ChannelSMS), WithChannelValidity(ChannelSMS, 0, 9), WithChannelValidity(ChannelViber, 9, 0))(&m)
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if s, want := m.Values().Get("channel_valid"), "viber:09:00,sms:00:09"; s != want {
+		t.Errorf("want %q, got %q", want, s)
+	}
+
+	WithChannelValidity(ChannelMail, 1, 0)(&m)
+	if err := m.Validate(); err != ErrChannelValid {
+		t.Errorf("want %v, got %v", ErrChannelValid, err)
+	}
+}
+
+func TestWithChannelValidity_panics(t *testing.T) {
+	defer func() {
+		if r := recover(); r != ErrBadValid {
+			t.Errorf("want %v, got %v", ErrBadValid, r)
+		}
+	}()
+	WithChannelValidity(ChannelSMS, 25, 0)
+}
+
+func TestResult_Channel_unknown(t *testing.T) {
+	var r Result
+	if err := json.Unmarshal([]byte(`{"id": 1, "cnt": 1, "channel": "telegram"}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Channel == nil || *r.Channel != ChannelUnknown {
+		t.Errorf("want %v, got %v", ChannelUnknown, r.Channel)
+	}
+}
+
+func TestResult_Channel(t *testing.T) {
+	var r Result
+	if err := json.Unmarshal([]byte(`{"id": 1, "cnt": 1, "channel": "viber"}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Channel == nil || *r.Channel != ChannelViber {
+		t.Errorf("want %v, got %v", ChannelViber, r.Channel)
+	}
+}
+
+var EstimatePartsTests = []struct {
+	Text  string
+	Parts int
+}{
+	{"", 0},
+	{Generate(abc, 160), 1},
+	{Generate(abc, 161), 2},
+	{Generate(abc, 306), 2},
+	{Generate(abc, 307), 3},
+	{Generate(abcRus, 70), 1},
+	{Generate(abcRus, 71), 2},
+	{Generate(abcRus, 134), 2},
+	{Generate(abcRus, 135), 3},
+}
+
+func TestEstimateParts(t *testing.T) {
+	for _, tt := range EstimatePartsTests {
+		if n := EstimateParts(tt.Text); n != tt.Parts {
+			t.Errorf("%d chars: want %d, got %d", utf8.RuneCountInString(tt.Text), tt.Parts, n)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package smsc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// windows1251Desc is "неверный номер" in windows-1251.
+const windows1251Desc = "\xed\xe5\xe2\xe5\xf0\xed\xfb\xe9 \xed\xee\xec\xe5\xf0"
+
+var DecodeBodyTests = []struct {
+	ContentType string
+	Body        string
+	Want        string
+	Err         error
+}{
+	{"", "привет", "привет", nil},
+	{"application/json", "привет", "привет", nil},
+	{"application/json; charset=utf-8", "привет", "привет", nil},
+	{"application/json; charset=windows-1251", windows1251Desc, "неверный номер", nil},
+	{"application/json; charset=CP1251", windows1251Desc, "неверный номер", nil},
+	{"text/plain; charset=koi8-r", "\xd0\xd2\xc9\xd7\xc5\xd4", "привет", nil},
+	{"text/plain; charset=latin1", "test", "", ErrBadCharset},
+}
+
+func TestDecodeBody(t *testing.T) {
+	for _, tt := range DecodeBodyTests {
+		t.Run(tt.ContentType, func(t *testing.T) {
+			b, err := decodeBody([]byte(tt.Body), tt.ContentType)
+			if err != tt.Err {
+				t.Fatalf("error: want %v, got %v", tt.Err, err)
+			}
+			if err == nil && string(b) != tt.Want {
+				t.Errorf("want %q, got %q", tt.Want, b)
+			}
+		})
+	}
+}
+
+func TestClient_Send_windows1251(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=windows-1251")
+		w.Write([]byte(`{"error": "` + windows1251Desc + `", "error_code": 7}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Error{Code: CodePhone, Desc: "неверный номер"}
+	if _, err := c.Send("test", somePhone); !reflect.DeepEqual(want, err) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+}
@@ -1,17 +1,37 @@
 package smsc
 
 import (
+	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const DefaultURL = "https://smsc.ru/sys/send.php"
 
-var ErrNoLoginPassword = errors.New("smsc: empty login or password")
+// DefaultMaxResponseBytes is a limit of a response body when
+// Config.MaxResponseBytes is 0. Responses of sends to MaxRecipients phones are
+// much shorter.
+const DefaultMaxResponseBytes = 10 << 20
+
+var (
+	ErrNoLoginPassword     = errors.New("smsc: empty login or password")
+	ErrBadPartnerID        = errors.New("smsc: partner id must be numeric")
+	ErrBadMaxResponseBytes = errors.New("smsc: negative max response bytes")
+)
 
 // Config is a Client config.
 type Config struct {
@@ -21,6 +41,107 @@ type Config struct {
 	PasswordMD5 string
 	Opt         Opt
 	Client      *http.Client
+
+	// PartnerID is a numeric id of a reseller sent with every request for
+	// revenue attribution. It is redacted in debug dumps.
+	PartnerID string
+
+	// PrefixTable adds countries to the default table of CIS countries or
+	// replaces them. It is used to tell countries of phones, e.g. by
+	// SenderFallback and WithLocalDeliveryWindow.
+	PrefixTable PrefixTable
+
+	// DefaultCountry is an ISO 3166-1 alpha-2 code, e.g. "RU". When set,
+	// phones in local format are converted to E.164 before sending.
+	DefaultCountry string
+
+	// Dedupe keeps keys of SendOnce for DedupeTTL. DefaultDedupeTTL is used
+	// when DedupeTTL is 0.
+	Dedupe    DedupeStore
+	DedupeTTL time.Duration
+
+	// QuietHours blocks non-transactional messages with ErrQuietHours.
+	QuietHours *QuietHours
+
+	// SenderFallback selects a sender of messages without Sender option.
+	SenderFallback *SenderFallback
+
+	// ChannelSenders are senders registered for channels of
+	// WithFallbackChannels.
+	ChannelSenders map[Channel]string
+
+	// StrictParsing makes fields unknown to the package a response error.
+	StrictParsing bool
+
+	// PreSend is called with every message before it is validated and sent.
+	// It may change the message, e.g. append an opt-out footer. Phones it
+	// returns are normalized, deduplicated and prechecked like others. An
+	// error aborts the send.
+	PreSend func(*Message) error
+
+	// LeastCostRouting sends messages without Sender option by the cheapest
+	// route of each recipient's operator. Operators are looked up with
+	// info.php and prices with Tariffs, so it costs extra requests. Operators
+	// are cached and looked up at once as by HLRPrecheck. A default route is
+	// used when an operator is unknown.
+	LeastCostRouting bool
+
+	// MinBalance blocks sends with ErrLowBalance when the balance is below it.
+	MinBalance Money
+
+	// ValidateSenderLocally checks Sender option against approved senders of
+	// the account and returns ErrSenderNotApproved before sending. Senders
+	// are fetched on the first send, use RefreshSenders to update them.
+	// WithForceSend skips the check.
+	ValidateSenderLocally bool
+
+	// HLRPrecheck drops phones which are not served by any operator before
+	// sending, see Reachable. Phones of unknown reachability are sent. Up to
+	// HLRConcurrency lookups are made at once and their results are cached
+	// for HLRTTL. Defaults are used for zero values, and for a negative
+	// HLRConcurrency.
+	HLRPrecheck    bool
+	HLRConcurrency int
+	HLRTTL         time.Duration
+
+	// TTLs of cached account data, see Refresh. Defaults are used for zero
+	// TTLs.
+	TariffsTTL time.Duration
+	SendersTTL time.Duration
+	BalanceTTL time.Duration
+
+	// MaxRetries is a number of times a send is repeated after an error
+	// which proves it was not processed, e.g. 503 response status,
+	// CodeTooManyRequests or a failed connection. Timeouts and other errors
+	// after which a message may be sent are repeated only for a message of
+	// WithDerivedID, which API does not send twice. A send is not repeated
+	// by default. RetryDelay is a pause before the first retry, it
+	// doubles with each next one up to MaxRetryDelay. A longer Retry-After
+	// of a response is honored. Defaults are used for zero delays. See
+	// WithRetryBudget to limit a total time of retries.
+	MaxRetries    int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+
+	// RateLimit limits requests to API per second when positive, so sends
+	// wait instead of failing with CodeTooManyRequests. Retries are limited
+	// too.
+	RateLimit float64
+
+	// MaxResponseBytes limits a response body, so a misbehaving endpoint
+	// cannot exhaust memory. Longer responses are ErrLargeResponse.
+	// DefaultMaxResponseBytes is used when it is 0, a negative limit is
+	// ErrBadMaxResponseBytes.
+	MaxResponseBytes int64
+
+	// Logger receives warnings, e.g. of WithForceSend. The standard logger is
+	// used when it is nil.
+	Logger *log.Logger
+
+	// DebugWriter receives dumps of requests and responses when set. A
+	// password is redacted. Only headers of multipart requests are dumped
+	// so uploaded files are not buffered in memory.
+	DebugWriter io.Writer
 }
 
 // New initializes a Client.
@@ -37,15 +158,104 @@ func New(cfg Config) (*Client, error) {
 	if cfg.PasswordMD5 == "" {
 		cfg.PasswordMD5 = hashPassword(cfg.Password)
 	}
+	if cfg.PartnerID != "" && !isDigits(cfg.PartnerID) {
+		return nil, ErrBadPartnerID
+	}
 	if cfg.Client == nil {
 		cfg.Client = http.DefaultClient
 	}
+	if cfg.DedupeTTL == 0 {
+		cfg.DedupeTTL = DefaultDedupeTTL
+	}
+	if cfg.HLRConcurrency <= 0 {
+		cfg.HLRConcurrency = DefaultHLRConcurrency
+	}
+	if cfg.HLRTTL == 0 {
+		cfg.HLRTTL = DefaultHLRTTL
+	}
+	if cfg.TariffsTTL == 0 {
+		cfg.TariffsTTL = DefaultTariffsTTL
+	}
+	if cfg.SendersTTL == 0 {
+		cfg.SendersTTL = DefaultSendersTTL
+	}
+	if cfg.BalanceTTL == 0 {
+		cfg.BalanceTTL = DefaultBalanceTTL
+	}
+	if cfg.MaxResponseBytes < 0 {
+		return nil, ErrBadMaxResponseBytes
+	}
+	if cfg.MaxResponseBytes == 0 {
+		cfg.MaxResponseBytes = DefaultMaxResponseBytes
+	}
+	if cfg.RetryDelay == 0 {
+		cfg.RetryDelay = DefaultRetryDelay
+	}
+	if cfg.MaxRetryDelay == 0 {
+		cfg.MaxRetryDelay = DefaultMaxRetryDelay
+	}
+	if cfg.SenderFallback != nil {
+		if err := cfg.SenderFallback.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.QuietHours != nil {
+		if err := cfg.QuietHours.validate(); err != nil {
+			return nil, err
+		}
+	}
+	prefixes, err := countries.merge(cfg.PrefixTable)
+	if err != nil {
+		return nil, err
+	}
+	var country *Country
+	if cfg.DefaultCountry != "" {
+		c, ok := prefixes[strings.ToUpper(cfg.DefaultCountry)]
+		if !ok {
+			return nil, ErrBadCountry
+		}
+		country = &c
+	}
 	c := &Client{
 		url:      cfg.URL,
 		login:    cfg.Login,
 		password: cfg.PasswordMD5,
+		plain:    cfg.Password,
+		partner:  cfg.PartnerID,
 		opt:      cfg.Opt,
 		http:     cfg.Client,
+		country:  country,
+		prefixes: prefixes,
+
+		dedupe:    cfg.Dedupe,
+		dedupeTTL: cfg.DedupeTTL,
+		quiet:     cfg.QuietHours,
+		fallback:  cfg.SenderFallback,
+		debug:     cfg.DebugWriter,
+		logger:    cfg.Logger,
+		senders:   cfg.ChannelSenders,
+		strict:    cfg.StrictParsing,
+		hook:      cfg.PreSend,
+		lcr:       cfg.LeastCostRouting,
+
+		validateSender: cfg.ValidateSenderLocally,
+		minBalance:     cfg.MinBalance,
+
+		cache: &accountCache{},
+		ttls:  ttls{cfg.TariffsTTL, cfg.SendersTTL, cfg.BalanceTTL},
+
+		hlrPrecheck:    cfg.HLRPrecheck,
+		hlrConcurrency: cfg.HLRConcurrency,
+		hlrTTL:         cfg.HLRTTL,
+		hlr:            &hlrCache{},
+
+		maxRetries: cfg.MaxRetries,
+		retryDelay: cfg.RetryDelay,
+		maxDelay:   cfg.MaxRetryDelay,
+		limiter:    newLimiter(cfg.RateLimit),
+		maxBody:    cfg.MaxResponseBytes,
+
+		now: time.Now,
 	}
 	return c, nil
 }
@@ -62,53 +272,668 @@ type Client struct {
 	url      string
 	login    string
 	password string
+	plain    string // of Config.Password for WithPlainPassword
+	partner  string
 	opt      Opt
 	http     *http.Client
+	country  *Country
+	prefixes PrefixTable
+
+	dedupe    DedupeStore
+	dedupeTTL time.Duration
+	quiet     *QuietHours
+	fallback  *SenderFallback
+	debug     io.Writer
+	logger    *log.Logger
+	senders   map[Channel]string
+	strict    bool
+	hook      func(*Message) error
+	lcr       bool
+
+	validateSender bool
+	minBalance     Money
+
+	cache *accountCache
+	ttls  ttls
+
+	hlrPrecheck    bool
+	hlrConcurrency int
+	hlrTTL         time.Duration
+	hlr            *hlrCache
+
+	maxRetries int
+	retryDelay time.Duration
+	maxDelay   time.Duration
+	limiter    *limiter // nil without Config.RateLimit
+	maxBody    int64
+
+	now func() time.Time
+}
+
+// WithCredentials returns a copy of c which uses login and password.
+//
+// The copy shares the rest of Config with c: the http.Client, the default Opt,
+// the rate limiter, so Config.RateLimit holds for all copies together, the
+// DedupeStore, so a key sent by one copy is a duplicate for others, the HLR
+// cache, the PreSend hook, the Logger and DebugWriter, ChannelSenders and
+// PartnerID. Cached account data is not shared. An empty login or password
+// is not checked, so use New to validate credentials.
+func (c *Client) WithCredentials(login, password string) *Client {
+	cc := *c
+	cc.login = login
+	cc.password = hashPassword(password)
+	cc.plain = password
+	cc.cache = &accountCache{}
+	return &cc
 }
 
+// Send sends text to phones.
+//
+// It is a shortcut for SendContext with context.Background.
 func (c *Client) Send(text string, phones []string, opts ...Opt) (*Result, error) {
+	return c.SendContext(context.Background(), text, phones, opts...)
+}
+
+// SendContext sends text to phones. ctx controls the request. When a message
+// sent by several requests fails after some of them were sent, a Result of
+// the sent ones is returned with a *PartialError.
+func (c *Client) SendContext(ctx context.Context, text string, phones []string, opts ...Opt) (*Result, error) {
 	m := c.prepare(text, phones, opts)
-	if err := m.Validate(); err != nil {
+	ctx, cancel := m.context(ctx)
+	defer cancel()
+	// Phones of the hook are normalized, deduplicated and checked as others.
+	if err := c.preSend(m); err != nil {
+		return nil, err
+	}
+	if c.country != nil {
+		if err := m.normalizePhones(*c.country); err != nil {
+			return nil, err
+		}
+	}
+	var duplicates int
+	if m.Dedup {
+		m.Phones, duplicates = dedupPhones(m.Phones)
+	}
+	if c.validateSender && m.Sender != "" && !m.Force {
+		if err := c.checkSender(ctx, m.Sender); err != nil {
+			return nil, err
+		}
+	}
+	routed := c.lcr && m.Sender == "" && m.ConcatRef == nil && m.Group == ""
+	fallback := c.fallback != nil && m.Sender == ""
+	if fallback {
+		m.Sender = c.fallback.sender(m.Phones, c.prefixes)
+	}
+	if err := c.check(m); err != nil {
+		return nil, err
+	}
+	if err := c.checkBalance(ctx, m); err != nil {
+		return nil, err
+	}
+	// Lookups are paid, so they are made for a message which passed other
+	// checks. A cost request sends nothing, so phones are not looked up.
+	var unreachable []string
+	if c.hlrPrecheck && len(m.Phones) > 0 && m.Cost != CostWithoutSend {
+		var err error
+		m.Phones, unreachable, err = c.precheck(ctx, m.Phones)
+		if err != nil {
+			return nil, err
+		}
+		if len(m.Phones) == 0 {
+			return nil, &UnreachableError{unreachable}
+		}
+		if fallback {
+			m.Sender = c.fallback.sender(m.Phones, c.prefixes)
+		}
+	}
+
+	r, err := c.dispatch(ctx, m, routed)
+	if r == nil {
 		return nil, err
 	}
+	r.Meta.Duplicates = duplicates
+	r.Meta.Unreachable = unreachable
+	c.updateBalance(r)
+	return r, err
+}
+
+// dispatch sends m by as many requests as its options need.
+func (c *Client) dispatch(ctx context.Context, m *message, routed bool) (*Result, error) {
+	switch {
+	case m.AutoSplit > 0:
+		return c.sendSplit(ctx, m, routed)
+	case m.ConcatRef != nil:
+		return c.sendParts(ctx, m)
+	case m.LocalWindow != nil:
+		return c.sendLocal(ctx, m, routed)
+	case routed:
+		return c.sendRouted(ctx, m)
+	}
+	return c.post(ctx, m)
+}
+
+// SendMessage sends m built with NewMessage. It is the same as SendContext
+// with options of m.
+func (c *Client) SendMessage(ctx context.Context, m *Message) (*Result, error) {
+	return c.SendContext(ctx, m.Text, m.Phones, m.options()...)
+}
 
-	resp, err := c.http.PostForm(c.url, m.Values())
+// post sends m as a form. A request is repeated after temporary errors.
+func (c *Client) post(ctx context.Context, m *message) (*Result, error) {
+	if err := m.setDerivedID(); err != nil {
+		return nil, err
+	}
+	var body string
+	if len(m.Phones) == 1 {
+		body = m.encode()
+	} else {
+		body = m.Values().Encode()
+	}
+	r, err := c.retry(ctx, m.RetryBudget, m.ID > 0, func() (*Result, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, strings.NewReader(body))
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return c.send(req, m.Format)
+	})
 	if err != nil {
-		return nil, wrapErr(err)
+		return nil, unsupportedCallback(m, err)
 	}
-	defer resp.Body.Close()
+	r.Meta.DerivedID = m.ID
+	return r, nil
+}
 
-	b, err := ioutil.ReadAll(resp.Body)
+// sendParts sends m.Parts() one by one and merges their Results. When a part
+// fails, Results of the sent ones are returned with a *PartialError.
+func (c *Client) sendParts(ctx context.Context, m *message) (*Result, error) {
+	parts, err := m.Parts()
 	if err != nil {
+		return nil, err
+	}
+	rr := make([]*Result, 0, len(parts))
+	for i, p := range parts {
+		r, err := c.post(ctx, p)
+		if err != nil {
+			// Every phone got the first part.
+			return partial(rr, mergeResults, i, len(parts), nil, err)
+		}
+		rr = append(rr, r)
+	}
+	return mergeResults(rr), nil
+}
+
+// SendFromFile sends text to phones read from r.
+//
+// Phones are uploaded as a multipart file instead of inline parameters, so
+// the list may be as large as the API accepts. r must contain phones separated
+// by commas or new lines. Phones are sent as is, Config.DefaultCountry is not
+// applied to them.
+func (c *Client) SendFromFile(ctx context.Context, text string, r io.Reader, opts ...Opt) (*Result, error) {
+	m := c.prepare(text, nil, opts)
+	ctx, cancel := m.context(ctx)
+	defer cancel()
+	m.PhonesFile = r
+	if err := c.preSend(m); err != nil {
+		return nil, err
+	}
+	if err := c.check(m); err != nil {
+		return nil, err
+	}
+	if err := c.checkBalance(ctx, m); err != nil {
+		return nil, err
+	}
+
+	req, err := c.multipartRequest(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(req, m.Format)
+}
+
+// SendEmail sends an e-mail with text and attachments to emails.
+func (c *Client) SendEmail(ctx context.Context, subject, text string, emails []string, attachments []Attachment, opts ...Opt) (*Result, error) {
+	m := c.prepare(text, emails, opts)
+	ctx, cancel := m.context(ctx)
+	defer cancel()
+	m.Mail = Mail
+	m.Subject = subject
+	m.Attachments = attachments
+	if err := c.preSend(m); err != nil {
+		return nil, err
+	}
+	if err := c.check(m); err != nil {
+		return nil, err
+	}
+	if err := c.checkBalance(ctx, m); err != nil {
+		return nil, err
+	}
+
+	req, err := c.multipartRequest(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(req, m.Format)
+}
+
+// check finalizes m and returns an error if it must not be sent.
+func (c *Client) check(m *message) error {
+	// With Valid set, a conflict is reported by Validate.
+	if !m.ExpiresAt.IsZero() && m.Valid == nil {
+		v, err := validUntil(m.sendTime(c.now()), m.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		m.Valid, m.ExpiresAt = v, time.Time{}
+	}
+	// A hash must not be sent instead of a plain password even by force.
+	if m.PlainPassword && m.Password == "" {
+		return ErrNoPlainPassword
+	}
+	if m.Force {
+		c.logf("smsc: WARNING: force send skips validation of a message to %d phones", len(m.Phones))
+	} else if err := m.Validate(); err != nil {
+		return err
+	}
+	return c.checkQuietHours(m)
+}
+
+// logf prints to Config.Logger or to the standard logger when it is not set.
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+		return
+	}
+	log.Printf(format, v...)
+}
+
+// preSend calls Config.PreSend hook with m.
+func (c *Client) preSend(m *message) error {
+	if c.hook == nil {
+		return nil
+	}
+	v := &Message{
+		Text:   m.Text,
+		Phones: append([]string(nil), m.Phones...),
+		Sender: m.Sender,
+	}
+	if err := c.hook(v); err != nil {
+		return err
+	}
+	m.Text, m.Phones, m.Sender = v.Text, v.Phones, v.Sender
+	return nil
+}
+
+// multipartRequest returns a request which uploads m as a multipart form.
+func (c *Client) multipartRequest(ctx context.Context, m *message) (*http.Request, error) {
+	// Pipe the body so files are never buffered in memory.
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		err := m.WriteMultipart(mw)
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, pr)
+	if err != nil {
+		pr.Close()
 		return nil, wrapErr(err)
 	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req, nil
+}
+
+// send does req and parses a Result from response of format f.
+func (c *Client) send(req *http.Request, f format) (*Result, error) {
+	start := time.Now()
+	b, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	meta := Meta{
+		Duration: time.Since(start),
+		Attempts: 1,
+		Host:     req.URL.Host,
+	}
+
+	var r *Result
+	if f == formatInlineVerbose {
+		r, err = parseTerse(b)
+	} else {
+		r, err = parseResult(b, c.strict)
+	}
+	if err != nil {
+		return nil, tooLong(err)
+	}
+	r.Meta = meta
+	return r, nil
+}
+
+// parseResult parses a Result or an Error from JSON response. If strict is
+// true, unknown fields are errors.
+func parseResult(b []byte, strict bool) (*Result, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, ErrEmptyResult
+	}
+	if strict {
+		var shape struct {
+			resultJSON
+			Desc json.RawMessage `json:"error"`
+			Code json.RawMessage `json:"error_code"`
+		}
+		if err := unmarshal(b, &shape, true); err != nil {
+			return nil, &ParseError{Body: b, Err: err}
+		}
+	}
+
+	// Result and Error are parsed separately because both have an id field.
+	var r *Result
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, &ParseError{Body: b, Err: err}
+	}
+	if e := parseError(b); e != nil {
+		if r != nil && len(r.Phones) > 0 {
+			e.PartialResult = r
+		}
+		return nil, e
+	}
+	if r == nil || r.isZero() {
+		return nil, ErrEmptyResult
+	}
+	return r, nil
+}
+
+var (
+	// ErrBadResponse is returned when a response cannot be parsed.
+	ErrBadResponse = errors.New("smsc: invalid response")
+	// ErrEmptyResult is returned when a response is neither a Result nor an
+	// Error.
+	ErrEmptyResult = errors.New("smsc: empty result")
+	// ErrLargeResponse is returned when a response body is longer than
+	// Config.MaxResponseBytes.
+	ErrLargeResponse = errors.New("smsc: response is too large")
+)
+
+// ParseError is returned when a response is not a valid JSON of an expected
+// shape. API errors are *Error.
+type ParseError struct {
+	Body []byte // of a response
+	Err  error  // of decoding
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("smsc: cannot parse response: %v", e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseTerse parses a Result or an Error from a plain text response, e.g.
+// "OK - 1 SMS, ID - 100" or "ERROR = 2 (authorise error)".
+func parseTerse(b []byte) (*Result, error) {
+	s := string(bytes.TrimSpace(b))
+
+	if rest, ok := cutPrefix(s, "ERROR = "); ok {
+		i := strings.IndexByte(rest, ' ')
+		if i < 0 {
+			return nil, ErrBadResponse
+		}
+		code, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return nil, ErrBadResponse
+		}
+		e := &Error{Code: code}
+		rest = rest[i+1:]
+		if j := strings.LastIndexByte(rest, ')'); strings.HasPrefix(rest, "(") && j > 0 {
+			e.Desc = rest[1:j]
+			rest = rest[j+1:]
+		}
+		if rest, ok := cutPrefix(rest, ", ID - "); ok {
+			id, err := strconv.ParseInt(rest, 10, 64)
+			if err != nil {
+				return nil, ErrBadResponse
+			}
+			e.ID = &id
+		}
+		return nil, e
+	}
+
+	rest, ok := cutPrefix(s, "OK - ")
+	if !ok {
+		return nil, ErrBadResponse
+	}
+	i := strings.Index(rest, " SMS, ID - ")
+	if i < 0 {
+		return nil, ErrBadResponse
+	}
+	count, err := strconv.Atoi(rest[:i])
+	if err != nil {
+		return nil, ErrBadResponse
+	}
+	rest = rest[i+len(" SMS, ID - "):]
+	if j := strings.IndexByte(rest, ','); j >= 0 {
+		rest = rest[:j] // cost and balance are not parsed
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil {
+		return nil, ErrBadResponse
+	}
+	return &Result{ID: id, Count: count}, nil
+}
 
-	// MetaResult allows to parse Result and Error from response while
-	// structures have different fields.
-	type MetaResult struct {
-		*Result
-		*Error
+// cutPrefix returns s without prefix and whether s had it.
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
 	}
+	return s[len(prefix):], true
+}
 
-	var mr *MetaResult
-	if err := json.Unmarshal(b, &mr); err != nil {
+// call posts form v to API script name and parses a response into r.
+//
+// Credentials and a response format are added to v.
+func (c *Client) call(ctx context.Context, name string, v url.Values, r interface{}) error {
+	_, err := c.callHeader(ctx, name, v, r)
+	return err
+}
+
+// callHeader is call which also returns response headers.
+func (c *Client) callHeader(ctx context.Context, name string, v url.Values, r interface{}) (http.Header, error) {
+	u, err := c.endpoint(name)
+	if err != nil {
 		return nil, wrapErr(err)
 	}
-	if mr.Error != nil {
-		return nil, mr.Error
+	v.Set("login", c.login)
+	v.Set("psw", c.password)
+	if c.partner != "" {
+		v.Set("pp", c.partner)
 	}
-	return mr.Result, nil
+	v.Set("charset", formatOpt(charsetUTF8))
+	v.Set("fmt", formatOpt(formatJSON))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	b, h, err := c.roundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if e := parseError(b); e != nil {
+		return h, e
+	}
+	if err := unmarshal(b, r, c.strict); err != nil {
+		return h, &ParseError{Body: b, Err: err}
+	}
+	return h, nil
+}
+
+// unmarshal parses JSON b into v. If strict is true, unknown fields are
+// errors.
+func unmarshal(b []byte, v interface{}, strict bool) error {
+	if !strict {
+		return json.Unmarshal(b, v)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
+
+// endpoint returns a URL of API script name, e.g. "balance.php". Scripts are
+// resolved relatively to Config.URL.
+func (c *Client) endpoint(name string) (string, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return "", err
+	}
+	return u.ResolveReference(&url.URL{Path: name}).String(), nil
+}
+
+// do does req and returns a response body.
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	b, _, err := c.roundTrip(req)
+	return b, err
+}
+
+// roundTrip is do which also returns response headers.
+func (c *Client) roundTrip(req *http.Request) ([]byte, http.Header, error) {
+	if c.debug != nil {
+		// Dumping a piped multipart body would read a whole file into memory.
+		body := !strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/")
+		b, err := httputil.DumpRequestOut(req, body)
+		if err != nil {
+			closeBody(req)
+			return nil, nil, wrapErr(err)
+		}
+		c.dump(b)
+	}
+
+	if err := c.limiter.wait(req.Context()); err != nil {
+		closeBody(req)
+		return nil, nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, wrapErr(err)
+	}
+	defer resp.Body.Close()
+	// A byte over the limit tells a body which is too large.
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(resp.Body, c.maxBody+1), resp.Body}
+
+	if c.debug != nil {
+		b, err := httputil.DumpResponse(resp, true)
+		if err != nil {
+			return nil, nil, wrapErr(err)
+		}
+		c.dump(b)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: retryAfter(resp.Header),
+		}
+	}
+
+	b, err := readBody(resp.Body)
+	if err != nil {
+		return nil, nil, wrapErr(err)
+	}
+	if int64(len(b)) > c.maxBody {
+		return nil, nil, ErrLargeResponse
+	}
+	// Proxies may re-encode a response, so a declared charset wins over the
+	// requested one.
+	b, err = decodeBody(b, resp.Header.Get("Content-Type"))
+	return b, resp.Header, err
+}
+
+// maxPooledBody limits a capacity of buffers kept in bodyPool, so a rare large
+// response does not stay in memory.
+const maxPooledBody = 64 << 10
+
+// bodyPool reuses buffers of response bodies.
+var bodyPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// readBody reads r into a pooled buffer and returns a copy of exact size, so
+// a body is not grown by ioutil.ReadAll chunks on each request.
+func readBody(r io.Reader) ([]byte, error) {
+	buf := bodyPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBody {
+			buf.Reset()
+			bodyPool.Put(buf)
+		}
+	}()
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), buf.Bytes()...), nil
+}
+
+// closeBody closes a body of req which is not sent, so a writer of a piped
+// body, e.g. of multipartRequest, does not block forever. http.Client.Do
+// closes it otherwise.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// redacted replaces secrets in debug dumps.
+const redacted = "********"
+
+// pswRe matches a password parameter of a URL encoded or a multipart form.
+var pswRe = regexp.MustCompile(`(\bpsw=)[^&\s]*|(name="psw"\r\n\r\n)[^\r]*`)
+
+// dump writes b to c.debug with secrets redacted. Only parameters are
+// redacted, so a text which contains a password is dumped as is.
+func (c *Client) dump(b []byte) {
+	b = pswRe.ReplaceAll(b, []byte("${1}${2}"+redacted))
+	if c.partner != "" {
+		// Only the parameter is redacted, the same digits may be in phones.
+		b = bytes.ReplaceAll(b, []byte("pp="+c.partner), []byte("pp="+redacted))
+		b = bytes.ReplaceAll(b, []byte("name=\"pp\"\r\n\r\n"+c.partner), []byte("name=\"pp\"\r\n\r\n"+redacted))
+	}
+	c.debug.Write(append(b, '\n'))
+}
+
+// parseError returns an *Error if b is an API error response.
+func parseError(b []byte) *Error {
+	var e Error
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil
+	}
+	if e.Code == 0 && e.Desc == "" {
+		return nil
+	}
+	return &e
 }
 
 // prepare returns a message ready to be sent.
 func (c *Client) prepare(text string, phones []string, opts []Opt) *message {
 	m := &message{
-		Login:    c.login,
-		Password: c.password,
-		Text:     text,
-		Phones:   phones,
-		Charset:  charsetUTF8,
-		Format:   formatJSON,
+		Login:     c.login,
+		Password:  c.password,
+		PartnerID: c.partner,
+		Text:      text,
+		Phones:    phones,
+		Charset:   charsetUTF8,
+		Format:    formatJSON,
+
+		ChannelSenders: c.senders,
 	}
 	if c.opt != nil {
 		c.opt(m)
@@ -116,6 +941,9 @@ func (c *Client) prepare(text string, phones []string, opts []Opt) *message {
 	for _, opt := range opts {
 		opt(m)
 	}
+	if m.PlainPassword {
+		m.Password = c.plain
+	}
 	return m
 }
 
@@ -124,15 +952,127 @@ func wrapErr(err error) error {
 	if err == nil {
 		return err
 	}
-	return fmt.Errorf("smsc: %s", err)
+	return fmt.Errorf("smsc: %w", err)
 }
 
 type Result struct {
-	ID      int     `json:"id"`
+	ID      int64   `json:"id"`
 	Count   int     `json:"cnt"`
 	Cost    *string `json:"cost"`
 	Balance *string `json:"balance"`
 	Phones  []Phone `json:"phones"`
+
+	// Parts holds Results of separate requests when a message was sent by
+	// parts. ID is of the first part and Count is a total then.
+	Parts []*Result `json:"-"`
+
+	// Channel reports which of WithFallbackChannels delivered a message.
+	Channel *Channel `json:"channel"`
+
+	// ScheduledAt is a time of delivery when API queued a message instead of
+	// sending it at once.
+	ScheduledAt *time.Time `json:"-"`
+
+	Meta Meta `json:"-"`
+}
+
+// Meta describes requests which returned a Result.
+type Meta struct {
+	Duration time.Duration // of HTTP round trips
+	Attempts int           // number of requests made
+	Host     string        // which served the last request
+
+	// Duplicates is a number of phones dropped by WithDedup.
+	Duplicates int
+	// Unreachable are phones dropped by Config.HLRPrecheck.
+	Unreachable []string
+	// DerivedID is an id set by WithDerivedID, of the first request.
+	DerivedID int64
+}
+
+// resultJSON is a JSON form of Result.
+type resultJSON struct {
+	resultFields
+	Time *int64 `json:"time"`
+}
+
+// resultFields has Result fields without UnmarshalJSON method.
+type resultFields Result
+
+func (r *Result) UnmarshalJSON(b []byte) error {
+	var aux resultJSON
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*r = Result(aux.resultFields)
+	if aux.Time != nil {
+		t := time.Unix(*aux.Time, 0)
+		r.ScheduledAt = &t
+	}
+	return nil
+}
+
+// CostMoney returns Cost as Money. Zero is returned when Cost is not set.
+func (r *Result) CostMoney() (Money, error) {
+	return parseOptionalMoney(r.Cost)
+}
+
+// BalanceMoney returns Balance as Money. Zero is returned when Balance is not
+// set.
+func (r *Result) BalanceMoney() (Money, error) {
+	return parseOptionalMoney(r.Balance)
+}
+
+// IsScheduled tells whether a message is queued for later delivery.
+func (r *Result) IsScheduled() bool {
+	return r.ScheduledAt != nil
+}
+
+// mergeResults returns a Result of a message sent by parts rr. Cost is a
+// total of parts, it is not set when a cost of any part is unknown. Balance
+// is of the last part which reported it.
+func mergeResults(rr []*Result) *Result {
+	r := &Result{Parts: rr}
+	var cost Money
+	costKnown := len(rr) > 0
+	for i, p := range rr {
+		if i == 0 {
+			r.ID = p.ID
+			r.Meta.DerivedID = p.Meta.DerivedID
+		}
+		r.Count += p.Count
+		r.Meta.Duration += p.Meta.Duration
+		r.Meta.Attempts += p.Meta.Attempts
+		r.Meta.Host = p.Meta.Host
+		if p.Balance != nil {
+			r.Balance = p.Balance
+		}
+		if m, err := parseOptionalMoney(p.Cost); err == nil && p.Cost != nil {
+			cost += m
+		} else {
+			costKnown = false
+		}
+	}
+	if costKnown {
+		s := cost.String()
+		r.Cost = &s
+	}
+	return r
+}
+
+// mergeBatches returns a Result of a message sent to separate groups of
+// phones rr.
+func mergeBatches(rr []*Result) *Result {
+	r := mergeResults(rr)
+	for _, p := range rr {
+		r.Phones = append(r.Phones, p.Phones...)
+	}
+	return r
+}
+
+// isZero tells whether r has no fields set.
+func (r *Result) isZero() bool {
+	return r.ID == 0 && r.Count == 0 && r.Cost == nil && r.Balance == nil && r.Phones == nil && r.Channel == nil
 }
 
 func (r *Result) String() string {
@@ -145,12 +1085,78 @@ type Phone struct {
 	Cost   string  `json:"cost"`
 	Status *string `json:"status"`
 	Error  *string `json:"error"`
+
+	// ErrorCode is a code of Error. API sends it with WithVerboseErrors.
+	ErrorCode *int `json:"error_code"`
+}
+
+// CostMoney returns Cost as Money. Zero is returned when Cost is empty.
+func (p *Phone) CostMoney() (Money, error) {
+	return parseOptionalMoney(&p.Cost)
+}
+
+// status returns a delivery state of p. A phone without a status is pending,
+// unless it has an error.
+func (p *Phone) status() Status {
+	if p.Status == nil {
+		if p.Error != nil {
+			return StatusRejected
+		}
+		return StatusQueued
+	}
+	code, err := strconv.Atoi(*p.Status)
+	if err != nil {
+		return StatusUnknown
+	}
+	return ParseStatus(code)
+}
+
+// DeliveredCount returns a number of Phones delivered by statuses of Op.
+func (r *Result) DeliveredCount() int {
+	return r.countStatus(StatusDelivered)
+}
+
+// RejectedCount returns a number of Phones which were rejected or cannot be
+// delivered.
+func (r *Result) RejectedCount() int {
+	return r.countStatus(StatusRejected, StatusUndeliverable)
+}
+
+func (r *Result) countStatus(ss ...Status) int {
+	var n int
+	for i := range r.Phones {
+		s := r.Phones[i].status()
+		for _, want := range ss {
+			if s == want {
+				n++
+				break
+			}
+		}
+	}
+	return n
 }
 
+// Error codes returned by API.
+const (
+	CodeParams = iota + 1
+	CodeAuth
+	CodeNoMoney
+	CodeBlocked
+	CodeDate
+	CodeForbidden
+	CodePhone
+	CodeUndeliverable
+	CodeTooManyRequests
+)
+
 type Error struct {
 	Code int    `json:"error_code"`
 	Desc string `json:"error"`
-	ID   *int   `json:"id"`
+	ID   *int64 `json:"id"`
+
+	// PartialResult holds phones processed before the error, if API returned
+	// them. It allows to reconcile partially billed sends.
+	PartialResult *Result `json:"-"`
 }
 
 func (e *Error) Error() string {
@@ -160,3 +1166,136 @@ func (e *Error) Error() string {
 	}
 	return s
 }
+
+// Temporary tells whether a request may succeed when repeated later.
+func (e *Error) Temporary() bool {
+	return temporaryCodes[e.Code]
+}
+
+// temporaryCodes lists API error codes of transient conditions.
+var temporaryCodes = map[int]bool{
+	CodeTooManyRequests: true,
+}
+
+// MessageTooLongError is returned when API rejects a message text as too long,
+// e.g. when Validate is skipped by WithForceSend. Length and Max are in
+// characters, they are 0 when API does not report them. Consider
+// WithAutoSplit to send a long text as separate messages.
+//
+// It matches ErrLongText with errors.Is and wraps an *Error of API.
+type MessageTooLongError struct {
+	Length, Max int
+	Err         *Error
+}
+
+func (e *MessageTooLongError) Error() string {
+	if e.Max == 0 {
+		return "smsc: message is too long"
+	}
+	return fmt.Sprintf("smsc: message of %d characters exceeds %d", e.Length, e.Max)
+}
+
+func (e *MessageTooLongError) Is(target error) bool {
+	return target == ErrLongText
+}
+
+func (e *MessageTooLongError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned by a send of a message by several requests, e.g.
+// by routes or parts, when a request fails after others were sent. The first
+// Sent of Total requests were sent, the next one failed with Err and the rest
+// were not sent. A Result of the sent requests is returned with it.
+//
+// Unsent are phones which got no request, as normalized for API. Other phones
+// got a part of the message at least, so resending to them bills them again.
+type PartialError struct {
+	Sent, Total int
+	Unsent      []string
+	Err         error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("smsc: %d of %d requests sent: %v", e.Sent, e.Total, e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// partial returns Results rr of sent requests merged by merge with a
+// *PartialError of a request sent of total which failed with err. err is
+// returned as is when nothing was sent.
+func partial(rr []*Result, merge func([]*Result) *Result, sent, total int, unsent []string, err error) (*Result, error) {
+	if len(rr) == 0 {
+		return nil, err
+	}
+	return merge(rr), &PartialError{Sent: sent, Total: total, Unsent: unsent, Err: err}
+}
+
+// unsentPhones returns phones of a request which failed with err and got no
+// message: PartialError.Unsent of a partly sent one, all phones otherwise.
+func unsentPhones(err error, phones []string) []string {
+	var e *PartialError
+	if errors.As(err, &e) {
+		return e.Unsent
+	}
+	return phones
+}
+
+// tooLongRe matches a description of an API error of a too long message, e.g.
+// "message is too long (length 1250, max 1000)".
+var tooLongRe = regexp.MustCompile(`(?i)too long(?:.*length (\d+))?(?:.*max (\d+))?`)
+
+// tooLong returns a *MessageTooLongError for an *Error of a too long message
+// and err otherwise.
+func tooLong(err error) error {
+	e, ok := err.(*Error)
+	if !ok || e.Code != CodeParams {
+		return err
+	}
+	sm := tooLongRe.FindStringSubmatch(e.Desc)
+	if sm == nil {
+		return err
+	}
+	length, _ := strconv.Atoi(sm[1])
+	max, _ := strconv.Atoi(sm[2])
+	return &MessageTooLongError{Length: length, Max: max, Err: e}
+}
+
+// HTTPError is returned when API responds with a non-2xx status.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration // of Retry-After header in seconds
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("smsc: unexpected response status %s", e.Status)
+}
+
+// Temporary tells whether a request may succeed when repeated later.
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// retryAfter returns a delay of Retry-After header h. Dates are not
+// supported.
+func retryAfter(h http.Header) time.Duration {
+	n, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second
+}
+
+// isTemporary tells whether err is a transient error. Both API errors and
+// wrapped net.Error values are checked.
+func isTemporary(err error) bool {
+	var t interface{ Temporary() bool }
+	if errors.As(err, &t) {
+		return t.Temporary()
+	}
+	return false
+}
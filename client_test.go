@@ -1,18 +1,25 @@
 package smsc
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 var (
-	id      = 1000
-	balance = "10.0"
-	cost    = "1.5"
+	id      int64 = 1000
+	balance       = "10.0"
+	cost          = "1.5"
 )
 
 var ResultStringTests = []struct {
@@ -33,6 +40,41 @@ func TestResult_String(t *testing.T) {
 	}
 }
 
+func TestResult_CostMoney(t *testing.T) {
+	comma := "1,5"
+	r := &Result{Cost: &comma, Balance: &balance}
+	if m, err := r.CostMoney(); err != nil || m != 150 {
+		t.Errorf("cost: want 1.50, got %v (%v)", m, err)
+	}
+	if m, err := r.BalanceMoney(); err != nil || m != 1000 {
+		t.Errorf("balance: want 10.00, got %v (%v)", m, err)
+	}
+	if m, err := (&Result{}).CostMoney(); err != nil || m != 0 {
+		t.Errorf("empty cost: want 0.00, got %v (%v)", m, err)
+	}
+}
+
+var PhoneCostMoneyTests = []struct {
+	Cost  string
+	Money Money
+	Err   error
+}{
+	{"", 0, nil},
+	{"0", 0, nil},
+	{"2.5", 250, nil},
+	{"1,75", 175, nil},
+	{"abc", 0, ErrBadMoney},
+}
+
+func TestPhone_CostMoney(t *testing.T) {
+	for _, tt := range PhoneCostMoneyTests {
+		p := &Phone{Cost: tt.Cost}
+		if m, err := p.CostMoney(); m != tt.Money || err != tt.Err {
+			t.Errorf("%q: want %v (%v), got %v (%v)", tt.Cost, tt.Money, tt.Err, m, err)
+		}
+	}
+}
+
 var ErrorErrorTests = []struct {
 	Err Error
 	S   string
@@ -77,10 +119,50 @@ var NewTests = []struct {
 		Config{Login: "test"},
 		ErrNoLoginPassword,
 	},
+	{
+		Config{Login: "test", Password: "pass", DefaultCountry: "ru"},
+		nil,
+	},
+	{
+		Config{Login: "test", Password: "pass", DefaultCountry: "XX"},
+		ErrBadCountry,
+	},
+	{
+		Config{Login: "test", Password: "pass", SenderFallback: &SenderFallback{Alpha: "Shop"}},
+		ErrBadSender,
+	},
 	{
 		Config{Password: "test"},
 		ErrNoLoginPassword,
 	},
+	{
+		Config{Login: "test", Password: "pass", MaxResponseBytes: -1},
+		ErrBadMaxResponseBytes,
+	},
+	{
+		Config{Login: "test", Password: "pass", QuietHours: &QuietHours{Start: 21 * time.Hour, End: 9 * time.Hour}},
+		nil,
+	},
+	{
+		Config{Login: "test", Password: "pass", QuietHours: &QuietHours{Start: 0, End: 24 * time.Hour}},
+		nil,
+	},
+	{
+		Config{Login: "test", Password: "pass", QuietHours: &QuietHours{Start: 9 * time.Hour, End: 9 * time.Hour}},
+		ErrBadQuietHours,
+	},
+	{
+		Config{Login: "test", Password: "pass", QuietHours: &QuietHours{Start: 25 * time.Hour, End: 9 * time.Hour}},
+		ErrBadQuietHours,
+	},
+	{
+		Config{Login: "test", Password: "pass", QuietHours: &QuietHours{Start: -time.Hour, End: 9 * time.Hour}},
+		ErrBadQuietHours,
+	},
+	{
+		Config{Login: "test", Password: "pass", QuietHours: &QuietHours{Start: 9 * time.Hour, End: 25 * time.Hour}},
+		ErrBadQuietHours,
+	},
 }
 
 func TestNew(t *testing.T) {
@@ -243,6 +325,19 @@ func TestClient_prepare(t *testing.T) {
 // 	}
 // }
 
+var bigID int64 = 1 << 32
+
+var scheduledAt = time.Unix(1577836800, 0)
+
+func TestResult_IsScheduled(t *testing.T) {
+	if (&Result{}).IsScheduled() {
+		t.Error("want false, got true")
+	}
+	if !(&Result{ScheduledAt: &scheduledAt}).IsScheduled() {
+		t.Error("want true, got false")
+	}
+}
+
 var ClientSendTests = []struct {
 	Value  interface{}
 	Result *Result
@@ -252,12 +347,75 @@ var ClientSendTests = []struct {
 		Value:  &Result{ID: 0, Count: 1},
 		Result: &Result{ID: 0, Count: 1},
 	},
+	{
+		Value:  &Result{ID: 10, Count: 1},
+		Result: &Result{ID: 10, Count: 1},
+	},
+	{
+		Value:  json.RawMessage(`{"id": 4294967296, "cnt": 1}`),
+		Result: &Result{ID: 1 << 32, Count: 1},
+	},
+	{
+		Value: json.RawMessage(`{"error": "invalid number", "error_code": 7, "id": 4294967296}`),
+		Err:   &Error{Code: 7, Desc: "invalid number", ID: &bigID},
+	},
 	{
 		Value: &Error{Code: 2},
 		Err:   &Error{Code: 2},
 	},
+	{
+		Value:  json.RawMessage(`{"id": 10, "cnt": 1, "time": 1577836800}`),
+		Result: &Result{ID: 10, Count: 1, ScheduledAt: &scheduledAt},
+	},
+	{
+		Value: json.RawMessage(`{}`),
+		Err:   ErrEmptyResult,
+	},
+	{
+		Value: json.RawMessage(`null`),
+		Err:   ErrEmptyResult,
+	},
+	{
+		Value: json.RawMessage(`{"error": "invalid number", "error_code": 7, "id": 1000,
+			"phones": [{"phone": "71234567890", "mccmnc": "25001", "cost": "1.5"}]}`),
+		Err: &Error{
+			Code: 7,
+			Desc: "invalid number",
+			ID:   &id,
+			PartialResult: &Result{
+				ID:     1000,
+				Phones: []Phone{{Phone: "71234567890", Mccmnc: "25001", Cost: "1.5"}},
+			},
+		},
+	},
+	{
+		Value: json.RawMessage(`{"id": 1000, "cnt": 1, "phones": [
+			{"phone": "71234567890", "mccmnc": "25001", "cost": "1.5"},
+			{"phone": "70000000000", "mccmnc": "", "cost": "0", "error": "invalid number", "error_code": 7}]}`),
+		Result: &Result{
+			ID:    1000,
+			Count: 1,
+			Phones: []Phone{
+				{Phone: "71234567890", Mccmnc: "25001", Cost: "1.5"},
+				{Phone: "70000000000", Cost: "0", Error: &invalidNumber, ErrorCode: &codePhone},
+			},
+		},
+	},
+	{
+		Value: json.RawMessage(`{"error": "message is too long (length 1250, max 1000)", "error_code": 1}`),
+		Err: &MessageTooLongError{
+			Length: 1250,
+			Max:    1000,
+			Err:    &Error{Code: CodeParams, Desc: "message is too long (length 1250, max 1000)"},
+		},
+	},
 }
 
+var (
+	invalidNumber = "invalid number"
+	codePhone     = CodePhone
+)
+
 func TestClient_Send(t *testing.T) {
 	minFields := 4 // login, psw, mes, phones
 
@@ -283,6 +441,12 @@ func TestClient_Send(t *testing.T) {
 			}
 
 			r, err := c.Send("A test message.", []string{"+71234567890"})
+			if r != nil {
+				if r.Meta.Attempts != 1 || r.Meta.Host == "" {
+					t.Errorf("meta: got %+v", r.Meta)
+				}
+				r.Meta = Meta{}
+			}
 			if !reflect.DeepEqual(tt.Err, err) {
 				t.Errorf("error: want %v, got %v", tt.Err, err)
 			}
@@ -292,3 +456,839 @@ func TestClient_Send(t *testing.T) {
 		})
 	}
 }
+
+var TooLongTests = []struct {
+	Err error
+	Len int
+	Max int
+}{
+	{&Error{Code: CodeParams, Desc: "message is too long"}, 0, 0},
+	{&Error{Code: CodeParams, Desc: "Message is too long (length 800, max 765)"}, 800, 765},
+	{&Error{Code: CodeParams, Desc: "invalid sender"}, -1, -1},
+	{&Error{Code: CodePhone, Desc: "too long"}, -1, -1},
+	{ErrBadResponse, -1, -1},
+}
+
+func TestTooLong(t *testing.T) {
+	for _, tt := range TooLongTests {
+		err := tooLong(tt.Err)
+		var e *MessageTooLongError
+		if !errors.As(err, &e) {
+			if tt.Len >= 0 || err != tt.Err {
+				t.Errorf("%v: unexpected %v", tt.Err, err)
+			}
+			continue
+		}
+		if e.Length != tt.Len || e.Max != tt.Max {
+			t.Errorf("%v: want %d and %d, got %d and %d", tt.Err, tt.Len, tt.Max, e.Length, e.Max)
+		}
+		if !errors.Is(err, ErrLongText) || !errors.Is(err, tt.Err) {
+			t.Errorf("%v: does not match", err)
+		}
+	}
+}
+
+func TestClient_SendFromFile(t *testing.T) {
+	phones := "+71234567890\n+71234567891\n"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if s := r.FormValue("mes"); s != "test" {
+			t.Errorf("mes: want %q, got %q", "test", s)
+		}
+		f, _, err := r.FormFile(phonesFileField)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != phones {
+			t.Errorf("file: want %q, got %q", phones, s)
+		}
+		json.NewEncoder(w).Encode(&Result{Count: 2})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := c.SendFromFile(context.Background(), "test", strings.NewReader(phones))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Meta = Meta{}
+	if want := (&Result{Count: 2}); !reflect.DeepEqual(r, want) {
+		t.Errorf("want %v, got %v", want, r)
+	}
+}
+
+func TestClient_SendFromFile_debugWriter(t *testing.T) {
+	phones := "+71234567890\n+71234567891\n"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile(phonesFileField)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if b, _ := ioutil.ReadAll(f); string(b) != phones {
+			t.Errorf("file: want %q, got %q", phones, b)
+		}
+		json.NewEncoder(w).Encode(&Result{Count: 2})
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", DebugWriter: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SendFromFile(context.Background(), "test", strings.NewReader(phones)); err != nil {
+		t.Fatal(err)
+	}
+
+	s := buf.String()
+	if !strings.Contains(s, "multipart/form-data") {
+		t.Errorf("dump has no headers:\n%s", s)
+	}
+	if strings.Contains(s, "+71234567890") {
+		t.Errorf("dump has a file:\n%s", s)
+	}
+}
+
+func TestClient_SendFromFile_partialResult(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error": "invalid number", "error_code": 7, "id": 1000,
+			"phones": [{"phone": "71234567890", "mccmnc": "25001", "cost": "1.5"}]}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.SendFromFile(context.Background(), "test", strings.NewReader("+71234567890\n+7000\n"))
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("want *Error, got %v", err)
+	}
+	want := &Result{ID: 1000, Phones: []Phone{{Phone: "71234567890", Mccmnc: "25001", Cost: "1.5"}}}
+	if !reflect.DeepEqual(e.PartialResult, want) {
+		t.Errorf("want %v, got %v", want, e.PartialResult)
+	}
+}
+
+func TestClient_WithCredentials(t *testing.T) {
+	c, err := New(Config{Login: "me", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := c.WithCredentials("test", pass)
+	if cc.login != "test" || cc.password != passHash {
+		t.Errorf("want %q/%q, got %q/%q", "test", passHash, cc.login, cc.password)
+	}
+	if cc.http != c.http {
+		t.Error("http.Client is not shared")
+	}
+	if c.login != "me" {
+		t.Errorf("original login changed: %q", c.login)
+	}
+}
+
+func TestClient_Send_normalizesPhones(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := r.PostFormValue("phones"); s != "+79161234567" {
+			t.Errorf("phones: want %q, got %q", "+79161234567", s)
+		}
+		json.NewEncoder(w).Encode(&Result{Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", DefaultCountry: "RU"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", []string{"8 (916) 123-45-67"}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Send("test", []string{"8 (916) 123-45-67", "+79161234567"}, WithDedup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Meta.Duplicates != 1 {
+		t.Errorf("duplicates: want 1, got %d", r.Meta.Duplicates)
+	}
+	if _, err := c.Send("test", []string{"123"}); !reflect.DeepEqual(err, &PhoneError{"123"}) {
+		t.Errorf("want %v, got %v", &PhoneError{"123"}, err)
+	}
+}
+
+var ParseTerseTests = []struct {
+	S      string
+	Result *Result
+	Err    error
+}{
+	{"OK - 1 SMS, ID - 100", &Result{ID: 100, Count: 1}, nil},
+	{"OK - 2 SMS, ID - 100, COST - 1.5, BALANCE - 10.0\n", &Result{ID: 100, Count: 2}, nil},
+	{"OK - 1 SMS, ID - 4294967296", &Result{ID: 1 << 32, Count: 1}, nil},
+	{"ERROR = 2 (authorise error)", nil, &Error{Code: 2, Desc: "authorise error"}},
+	{"ERROR = 7 (invalid number), ID - 1000", nil, &Error{Code: 7, Desc: "invalid number", ID: &id}},
+	{"OK - many SMS", nil, ErrBadResponse},
+	{"", nil, ErrBadResponse},
+}
+
+func TestParseTerse(t *testing.T) {
+	for _, tt := range ParseTerseTests {
+		t.Run(tt.S, func(t *testing.T) {
+			r, err := parseTerse([]byte(tt.S))
+			if !reflect.DeepEqual(tt.Err, err) {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+			if !reflect.DeepEqual(tt.Result, r) {
+				t.Errorf("result: want %v, got %v", tt.Result, r)
+			}
+		})
+	}
+}
+
+func TestClient_Send_terse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := r.PostForm["fmt"]; ok {
+			t.Errorf("fmt: want none, got %q", r.PostFormValue("fmt"))
+		}
+		fmt.Fprint(w, "OK - 1 SMS, ID - 100")
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Send("test", []string{"+71234567890"}, WithTerseResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Meta = Meta{}
+	if want := (&Result{ID: 100, Count: 1}); !reflect.DeepEqual(want, r) {
+		t.Errorf("want %v, got %v", want, r)
+	}
+}
+
+func TestClient_SendMessage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := r.PostFormValue("sender"); s != "Shop" {
+			t.Errorf("sender: want %q, got %q", "Shop", s)
+		}
+		if s := r.PostFormValue("flash"); s != "1" {
+			t.Errorf("flash: want 1, got %q", s)
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMessage("test", somePhone).Sender("Shop").Flash().Build()
+	if _, err := c.SendMessage(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_Send_emptyBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", []string{"+71234567890"}); err != ErrEmptyResult {
+		t.Errorf("want %v, got %v", ErrEmptyResult, err)
+	}
+}
+
+var ParseResultStrictTests = []struct {
+	S   string
+	Err bool
+}{
+	{`{"id": 1, "cnt": 1, "cost": "1.5", "balance": "10.0", "time": 1577836800}`, false},
+	{`{"id": 1, "cnt": 1, "phones": [{"phone": "7", "mccmnc": "25001", "cost": "1.5"}]}`, false},
+	{`{"error": "authorise error", "error_code": 2, "id": 1}`, false},
+	{`{"id": 1, "cnt": 1, "bonus": "1.0"}`, true},
+	{`{"id": 1, "cnt": 1, "phones": [{"phone": "7", "region": "Moscow"}]}`, true},
+}
+
+func TestParseResult_strict(t *testing.T) {
+	for _, tt := range ParseResultStrictTests {
+		t.Run(tt.S, func(t *testing.T) {
+			_, err := parseResult([]byte(tt.S), true)
+			if _, ok := err.(*Error); ok {
+				err = nil
+			}
+			if (err != nil) != tt.Err {
+				t.Errorf("want error %v, got %v", tt.Err, err)
+			}
+			// Lenient parsing never fails on unknown fields.
+			if _, err := parseResult([]byte(tt.S), false); err != nil {
+				if _, ok := err.(*Error); !ok {
+					t.Errorf("lenient: %v", err)
+				}
+			}
+		})
+	}
+}
+
+var ParseResultErrorTests = []struct {
+	S     string
+	Parse bool // whether a *ParseError is expected
+}{
+	{`{"id": 1, "cnt": 1`, true},
+	{`OK - 1 SMS, ID - 1`, true},
+	{`{"id": "1", "cnt": 1}`, true},
+	{`{"error": "authorise error", "error_code": 2}`, false},
+}
+
+func TestParseResult_errors(t *testing.T) {
+	for _, tt := range ParseResultErrorTests {
+		t.Run(tt.S, func(t *testing.T) {
+			_, err := parseResult([]byte(tt.S), false)
+			var pe *ParseError
+			var ae *Error
+			switch {
+			case tt.Parse && !errors.As(err, &pe):
+				t.Errorf("want *ParseError, got %v", err)
+			case tt.Parse && string(pe.Body) != tt.S:
+				t.Errorf("body: want %q, got %q", tt.S, pe.Body)
+			case !tt.Parse && !errors.As(err, &ae):
+				t.Errorf("want *Error, got %v", err)
+			}
+		})
+	}
+}
+
+func BenchmarkParseTerse(b *testing.B) {
+	body := []byte("OK - 1 SMS, ID - 100")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseTerse(body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseResult(b *testing.B) {
+	body := []byte(`{"id": 100, "cnt": 1}`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseResult(body, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// roundTripFunc is an http.RoundTripper of a function, so benchmarks of a
+// Client measure no network.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func BenchmarkClient_Send(b *testing.B) {
+	body := `{"id": 100, "cnt": 1}`
+	hc := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r.Body.Close()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Header:     http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})}
+	c, err := New(Config{Login: "test", Password: "pass", Client: hc})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Send("Your code is 123456", somePhone, Sender("Shop")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestClient_cancelledContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request is sent")
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := map[string]func() error{
+		"SendContext": func() error {
+			_, err := c.SendContext(ctx, "test", []string{"+71234567890"})
+			return err
+		},
+		"SendFromFile": func() error {
+			_, err := c.SendFromFile(ctx, "test", strings.NewReader("+71234567890"))
+			return err
+		},
+		"Tariffs": func() error {
+			_, err := c.Tariffs(ctx)
+			return err
+		},
+		"SendEmail": func() error {
+			_, err := c.SendEmail(ctx, "subj", "test", []string{"me@example.com"}, nil)
+			return err
+		},
+		"SendToGroup": func() error {
+			_, err := c.SendToGroup(ctx, "VIP", "test")
+			return err
+		},
+		"Cost": func() error {
+			_, err := c.Cost(ctx, "test", somePhone)
+			return err
+		},
+		"Balance": func() error {
+			_, err := c.Balance(ctx)
+			return err
+		},
+		"ProjectedBalance": func() error {
+			_, _, err := c.ProjectedBalance(ctx)
+			return err
+		},
+		"Status": func() error {
+			_, err := c.Status(ctx, id, "+71234567890")
+			return err
+		},
+		"Statuses": func() error {
+			_, err := c.Statuses(ctx, []Tracked{{id, "+71234567890"}})
+			return err
+		},
+		"Cancel": func() error {
+			return c.Cancel(ctx, id, "+71234567890")
+		},
+		"Reschedule": func() error {
+			_, err := c.Reschedule(ctx, id, "+71234567890", "test", day)
+			return err
+		},
+		"Senders": func() error {
+			_, err := c.Senders(ctx)
+			return err
+		},
+		"Groups": func() error {
+			_, err := c.Groups(ctx)
+			return err
+		},
+		"Inbox": func() error {
+			_, err := c.Inbox(ctx, day, day.Add(24*time.Hour))
+			return err
+		},
+		"InboxUnread": func() error {
+			_, err := c.InboxUnread(ctx, day, day.Add(24*time.Hour))
+			return err
+		},
+		"HistoryIterator": func() error {
+			it := c.HistoryIterator(ctx, day, day.Add(24*time.Hour))
+			for it.Next() {
+			}
+			return it.Err()
+		},
+		"Reachable": func() error {
+			_, _, err := c.Reachable(ctx, "+71234567890")
+			return err
+		},
+		"Preview": func() error {
+			_, err := c.Preview(ctx, "test", somePhone)
+			return err
+		},
+		"PingInfo": func() error {
+			_, err := c.PingInfo(ctx)
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
+
+func TestClient_SendEmail(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if s := r.FormValue("mail"); s != formatOpt(Mail) {
+			t.Errorf("mail: want %q, got %q", formatOpt(Mail), s)
+		}
+		if s := r.FormValue("subj"); s != "Invoice" {
+			t.Errorf("subj: want %q, got %q", "Invoice", s)
+		}
+		f, h, err := r.FormFile("file1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if h.Filename != "invoice.pdf" {
+			t.Errorf("filename: want %q, got %q", "invoice.pdf", h.Filename)
+		}
+		json.NewEncoder(w).Encode(&Result{ID: 1, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.SendEmail(context.Background(), "Invoice", "See attached.", []string{"me@example.com"},
+		[]Attachment{{Name: "invoice.pdf", Data: []byte("%PDF")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_Send_timeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Send("test", []string{"+71234567890"}, WithTimeout(10*time.Millisecond))
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
+
+var TemporaryTests = []struct {
+	Err       error
+	Temporary bool
+}{
+	{&Error{Code: CodeTooManyRequests}, true},
+	{&Error{Code: CodeAuth}, false},
+	{&Error{Code: CodeNoMoney}, false},
+	{&HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+	{&HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+	{&HTTPError{StatusCode: http.StatusNotFound}, false},
+	{ErrNoPhones, false},
+}
+
+func TestTemporary(t *testing.T) {
+	for _, tt := range TemporaryTests {
+		t.Run(tt.Err.Error(), func(t *testing.T) {
+			if v := isTemporary(tt.Err); v != tt.Temporary {
+				t.Errorf("want %v, got %v", tt.Temporary, v)
+			}
+		})
+	}
+}
+
+func TestClient_Send_httpError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Send("test", []string{"+71234567890"})
+	if e, ok := err.(*HTTPError); !ok || e.StatusCode != http.StatusBadGateway {
+		t.Errorf("want *HTTPError %d, got %v", http.StatusBadGateway, err)
+	}
+}
+
+func TestClient_Send_debugWriter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&Result{ID: 1, Count: 1})
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: pass, DebugWriter: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("debug me", []string{"+71234567890"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := buf.String()
+	if strings.Contains(s, passHash) {
+		t.Error("password is not redacted")
+	}
+	for _, want := range []string{"POST", "mes=debug+me", "psw=" + redacted, `"cnt":1`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("dump has no %q:\n%s", want, s)
+		}
+	}
+}
+
+func TestClient_partnerID(t *testing.T) {
+	if _, err := New(Config{Login: "test", Password: "pass", PartnerID: "12a"}); err != ErrBadPartnerID {
+		t.Errorf("want %v, got %v", ErrBadPartnerID, err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := r.PostFormValue("pp"); s != "4321" {
+			t.Errorf("%s: pp: want %q, got %q", r.URL.Path, "4321", s)
+		}
+		if r.URL.Path == "/tariffs.php" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		json.NewEncoder(w).Encode(&Result{ID: 1, Count: 1})
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: pass, PartnerID: "4321", DebugWriter: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", []string{"+71234567890"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Tariffs(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); strings.Contains(s, "4321") || !strings.Contains(s, "pp="+redacted) {
+		t.Errorf("partner id is not redacted:\n%s", s)
+	}
+}
+
+func TestClient_Send_preSend(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := r.PostFormValue("mes"); s != "Sale! STOP to unsubscribe" {
+			t.Errorf("mes: got %q", s)
+		}
+		json.NewEncoder(w).Encode(&Result{ID: 1, Count: 1})
+	}))
+	defer ts.Close()
+
+	errBlocked := errors.New("blocked")
+	c, err := New(Config{
+		URL:      ts.URL,
+		Login:    "test",
+		Password: "pass",
+		PreSend: func(m *Message) error {
+			if m.Sender == "spam" {
+				return errBlocked
+			}
+			m.Text += " STOP to unsubscribe"
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Send("Sale!", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("Sale!", somePhone, Sender("spam")); err != errBlocked {
+		t.Errorf("want %v, got %v", errBlocked, err)
+	}
+}
+
+func TestClient_Send_preSendPhones(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := r.PostFormValue("phones"); s != "+79161234567" {
+			t.Errorf("phones: want %q, got %q", "+79161234567", s)
+		}
+		json.NewEncoder(w).Encode(&Result{Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{
+		URL:            ts.URL,
+		Login:          "test",
+		Password:       "pass",
+		DefaultCountry: "RU",
+		PreSend: func(m *Message) error {
+			m.Phones = append(m.Phones, "8 (916) 123-45-67")
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Send("test", []string{"+79161234567"}, WithDedup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Meta.Duplicates != 1 {
+		t.Errorf("duplicates: want 1, got %d", r.Meta.Duplicates)
+	}
+}
+
+func TestClient_Send_plainPassword(t *testing.T) {
+	var psw string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		psw = r.PostFormValue("psw")
+		json.NewEncoder(w).Encode(&Result{ID: 1, Count: 1})
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: pass, DebugWriter: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", somePhone, WithPlainPassword()); err != nil {
+		t.Fatal(err)
+	}
+	if psw != pass {
+		t.Errorf("psw: want %q, got %q", pass, psw)
+	}
+	if strings.Contains(buf.String(), "psw="+pass) {
+		t.Error("plain password is not redacted")
+	}
+	if _, err := c.Send("test", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	if psw != passHash {
+		t.Errorf("psw: want %q, got %q", passHash, psw)
+	}
+
+	c, err = New(Config{URL: ts.URL, Login: "test", PasswordMD5: passHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", somePhone, WithPlainPassword()); err != ErrNoPlainPassword {
+		t.Errorf("want %v, got %v", ErrNoPlainPassword, err)
+	}
+	if _, err := c.Send("test", somePhone, WithPlainPassword(), WithForceSend()); err != ErrNoPlainPassword {
+		t.Errorf("force: want %v, got %v", ErrNoPlainPassword, err)
+	}
+}
+
+func TestClient_Send_plainPasswordText(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&Result{ID: 1, Count: 1})
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: pass, DebugWriter: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("your pass is ready", somePhone, WithPlainPassword()); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	for _, want := range []string{"mes=your+pass+is+ready", "psw=" + redacted} {
+		if !strings.Contains(s, want) {
+			t.Errorf("dump has no %q:\n%s", want, s)
+		}
+	}
+	if strings.Contains(s, "psw="+pass) {
+		t.Errorf("plain password is not redacted:\n%s", s)
+	}
+}
+
+func TestClient_Send_force(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&Result{ID: 1, Count: 1})
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", Logger: log.New(&buf, "", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := Generate(abc, smsMaxSize+1)
+	if _, err := c.Send(text, somePhone); err != ErrLongText {
+		t.Fatalf("want %v, got %v", ErrLongText, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected log: %s", buf.String())
+	}
+	if _, err := c.Send(text, somePhone, WithForceSend()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "WARNING") {
+		t.Errorf("no warning is logged: %q", buf.String())
+	}
+}
+
+func TestResult_DeliveredCount(t *testing.T) {
+	r := &Result{}
+	if err := json.Unmarshal([]byte(`{"id": 1, "cnt": 6, "phones": [
+		{"phone": "1", "status": "1"},
+		{"phone": "2", "status": "2"},
+		{"phone": "3", "status": "-1"},
+		{"phone": "4"},
+		{"phone": "5", "status": "22"},
+		{"phone": "6", "status": "20"},
+		{"phone": "7", "error": "invalid number"},
+		{"phone": "8", "status": "x"}
+	]}`), r); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.DeliveredCount(); n != 2 {
+		t.Errorf("delivered: want 2, got %d", n)
+	}
+	if n := r.RejectedCount(); n != 3 {
+		t.Errorf("rejected: want 3, got %d", n)
+	}
+}
+
+func TestConfig_MaxResponseBytes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %d, "cnt": 1}`, id)
+	}))
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		Max int64
+		Err error
+	}{
+		{0, nil},
+		{1000, nil},
+		{int64(len(fmt.Sprintf(`{"id": %d, "cnt": 1}`, id))), nil},
+		{10, ErrLargeResponse},
+	} {
+		c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", MaxResponseBytes: tt.Max})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Send("test", somePhone); err != tt.Err {
+			t.Errorf("%d: want %v, got %v", tt.Max, tt.Err, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultDedupeTTL is used when Config.DedupeTTL is not set.
+const DefaultDedupeTTL = time.Hour
+
+var ErrNoDedupe = errors.New("smsc: no dedupe store")
+
+// DedupeStore keeps Results of sends by caller keys, so SendOnce doesn't send
+// a message twice. Implement it to share keys between processes, e.g. with
+// Redis.
+//
+// Implementations must be safe for concurrent use.
+type DedupeStore interface {
+	// Get returns a Result stored by key, or false if there is none.
+	Get(ctx context.Context, key string) (*Result, bool, error)
+	// Set stores r by key for ttl.
+	Set(ctx context.Context, key string, r *Result, ttl time.Duration) error
+}
+
+// SendOnce sends text to phones unless a message with key was sent within
+// Config.DedupeTTL. In that case the Result of the first send is returned.
+//
+// Keys are scoped by a login, so copies of WithCredentials sharing a store do
+// not hide sends of each other. Concurrent calls with the same key are not
+// synchronized, so both can send.
+//
+// A partly sent message is stored too and its Result is returned with a
+// *PartialError. Later calls with the key return the partial Result without
+// an error, so sent parts are not sent and billed again.
+func (c *Client) SendOnce(ctx context.Context, key, text string, phones []string, opts ...Opt) (*Result, error) {
+	if c.dedupe == nil {
+		return nil, ErrNoDedupe
+	}
+	key = dedupeKey(c.login, key)
+
+	r, ok, err := c.dedupe.Get(ctx, key)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	if ok {
+		return r, nil
+	}
+
+	r, err = c.SendContext(ctx, text, phones, opts...)
+	if r == nil {
+		return nil, err
+	}
+	// The message is sent already, so return r with an error.
+	if err := c.dedupe.Set(ctx, key, r, c.dedupeTTL); err != nil {
+		return r, wrapErr(err)
+	}
+	return r, err
+}
+
+// dedupeKey returns a key of a store for a caller key of login. A login is
+// escaped, so a key cannot be mistaken for one of another login.
+func dedupeKey(login, key string) string {
+	return url.QueryEscape(login) + ":" + key
+}
+
+// minDedupePrune is a number of keys of MemoryDedupe before expired keys are
+// dropped for the first time.
+const minDedupePrune = 64
+
+// MemoryDedupe is a DedupeStore which keeps keys in memory.
+type MemoryDedupe struct {
+	mu    sync.Mutex
+	items map[string]dedupeItem
+	prune int // number of keys when expired keys are dropped
+	now   func() time.Time
+}
+
+type dedupeItem struct {
+	Result  *Result
+	Expires time.Time
+}
+
+// NewMemoryDedupe returns an empty MemoryDedupe.
+func NewMemoryDedupe() *MemoryDedupe {
+	return &MemoryDedupe{
+		items: make(map[string]dedupeItem),
+		prune: minDedupePrune,
+		now:   time.Now,
+	}
+}
+
+func (d *MemoryDedupe) Get(ctx context.Context, key string) (*Result, bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item, ok := d.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !d.now().Before(item.Expires) {
+		delete(d.items, key)
+		return nil, false, nil
+	}
+	return copyResult(item.Result), true, nil
+}
+
+func (d *MemoryDedupe) Set(ctx context.Context, key string, r *Result, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	// Drop expired keys, so the map doesn't grow forever. The map is scanned
+	// when it doubles, so a scan costs O(1) per Set on average.
+	if len(d.items) >= d.prune {
+		for k, item := range d.items {
+			if !now.Before(item.Expires) {
+				delete(d.items, k)
+			}
+		}
+		if d.prune = 2 * len(d.items); d.prune < minDedupePrune {
+			d.prune = minDedupePrune
+		}
+	}
+	d.items[key] = dedupeItem{Result: copyResult(r), Expires: now.Add(ttl)}
+	return nil
+}
+
+// copyResult returns a copy of r which shares no slices or pointers with it,
+// so a stored Result cannot be changed by a caller.
+func copyResult(r *Result) *Result {
+	if r == nil {
+		return nil
+	}
+	cp := *r
+	cp.Cost = copyPtr(r.Cost)
+	cp.Balance = copyPtr(r.Balance)
+	if r.Channel != nil {
+		ch := *r.Channel
+		cp.Channel = &ch
+	}
+	if r.ScheduledAt != nil {
+		t := *r.ScheduledAt
+		cp.ScheduledAt = &t
+	}
+	if r.Phones != nil {
+		cp.Phones = make([]Phone, len(r.Phones))
+		for i, p := range r.Phones {
+			p.Status, p.Error = copyPtr(p.Status), copyPtr(p.Error)
+			if p.ErrorCode != nil {
+				code := *p.ErrorCode
+				p.ErrorCode = &code
+			}
+			cp.Phones[i] = p
+		}
+	}
+	cp.Meta.Unreachable = append([]string(nil), r.Meta.Unreachable...)
+	if r.Parts != nil {
+		cp.Parts = make([]*Result, len(r.Parts))
+		for i, p := range r.Parts {
+			cp.Parts[i] = copyResult(p)
+		}
+	}
+	return &cp
+}
+
+// copyPtr returns a pointer to a copy of *s, or nil.
+func copyPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
@@ -0,0 +1,176 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryDedupe(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	d := NewMemoryDedupe()
+	d.now = func() time.Time { return now }
+
+	if _, ok, _ := d.Get(ctx, "key"); ok {
+		t.Fatal("empty store has a key")
+	}
+	r := &Result{ID: 1, Count: 1}
+	if err := d.Set(ctx, "key", r, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, _ := d.Get(ctx, "key")
+	if !ok || !reflect.DeepEqual(got, r) {
+		t.Fatalf("want %v, got %v", r, got)
+	}
+	// A stored Result is not changed by callers.
+	r.Count, got.ID = 2, 2
+	if got, _, _ := d.Get(ctx, "key"); got.ID != 1 || got.Count != 1 {
+		t.Errorf("stored result is changed: %v", got)
+	}
+
+	status := "1"
+	r = &Result{ID: 1, Phones: []Phone{{Phone: "71", Status: &status}}}
+	if err := d.Set(ctx, "phones", r, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	*r.Phones[0].Status = "2"
+	if got, _, _ := d.Get(ctx, "phones"); *got.Phones[0].Status != "1" {
+		t.Errorf("stored phone status is changed: %q", *got.Phones[0].Status)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := d.Get(ctx, "key"); ok {
+		t.Fatal("key is not expired")
+	}
+}
+
+func TestMemoryDedupe_prune(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	d := NewMemoryDedupe()
+	d.now = func() time.Time { return now }
+	for i := 0; i < 10*minDedupePrune; i++ {
+		if err := d.Set(ctx, fmt.Sprint(i), &Result{}, time.Second); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(100 * time.Millisecond)
+	}
+	// Keys of the last second are alive, expired ones are dropped once the
+	// map doubles.
+	if n := len(d.items); n > 2*minDedupePrune {
+		t.Errorf("want %d keys at most, got %d", 2*minDedupePrune, n)
+	}
+}
+
+func TestClient_SendOnce_credentials(t *testing.T) {
+	var sent int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		json.NewEncoder(w).Encode(&Result{ID: sent, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", Dedupe: NewMemoryDedupe()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := c.SendOnce(ctx, "key", "test", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WithCredentials("other", "pass").SendOnce(ctx, "key", "test", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Errorf("sent: want 2, got %d", sent)
+	}
+}
+
+func TestClient_SendOnce(t *testing.T) {
+	var sent int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		json.NewEncoder(w).Encode(&Result{ID: sent, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", Dedupe: NewMemoryDedupe()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	phones := []string{"+71234567890"}
+	want := &Result{ID: 1, Count: 1}
+	for i := 0; i < 2; i++ {
+		r, err := c.SendOnce(ctx, "key", "test", phones)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Meta = Meta{}
+		if !reflect.DeepEqual(want, r) {
+			t.Errorf("%d: want %v, got %v", i, want, r)
+		}
+	}
+	if sent != 1 {
+		t.Errorf("sent: want 1, got %d", sent)
+	}
+
+	if _, err := c.SendOnce(ctx, "other", "test", phones); err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Errorf("sent: want 2, got %d", sent)
+	}
+}
+
+func TestClient_SendOnce_noStore(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SendOnce(context.Background(), "key", "test", nil); err != ErrNoDedupe {
+		t.Errorf("want %v, got %v", ErrNoDedupe, err)
+	}
+}
+
+func TestClient_SendOnce_partial(t *testing.T) {
+	var sent int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sent++; sent > 1 {
+			w.Write([]byte(`{"error": "invalid number", "error_code": 7}`))
+			return
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", Dedupe: NewMemoryDedupe()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	text := Generate(abc+" ", 2*smsLatinChars)
+	r, err := c.SendOnce(ctx, "key", text, somePhone, WithAutoSplit(smsLatinChars))
+	var perr *PartialError
+	if !errors.As(err, &perr) || r == nil {
+		t.Fatalf("want a Result with *PartialError, got %v, %v", r, err)
+	}
+	n := sent
+	r, err = c.SendOnce(ctx, "key", text, somePhone, WithAutoSplit(smsLatinChars))
+	if err != nil || r == nil || r.ID != id {
+		t.Errorf("want a stored partial Result, got %v, %v", r, err)
+	}
+	if sent != n {
+		t.Errorf("want no requests of a stored key, got %d", sent-n)
+	}
+}
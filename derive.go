@@ -0,0 +1,64 @@
+package smsc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+var ErrBadDerivedID = errors.New("smsc: derived id is out of range")
+
+// maxMessageID is a maximum id of a message API accepts.
+const maxMessageID = 1<<31 - 1
+
+// WithDerivedID sets an id of a message derived from its text, phones and
+// salt. API rejects a message with a known id for 24 hours, so identical
+// retries are not sent twice without a local store like SendOnce needs.
+// Result.Meta.DerivedID reports the id.
+//
+// Phones are sorted, so their order does not change the id. A message sent by
+// several requests, e.g. with WithAutoSplit, has an id per request. The salt
+// separates messages which are the same by intent, e.g. two reminders. It has
+// no effect on SendFromFile and SendEmail.
+func WithDerivedID(salt string) Opt {
+	return func(m *message) {
+		m.DeriveID = true
+		m.IDSalt = salt
+	}
+}
+
+// deriveID returns an id in [1, maxMessageID] of text, phones and salt.
+func deriveID(text string, phones []string, salt string) int64 {
+	sorted := append([]string(nil), phones...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(strconv.Itoa(len(s))))
+		h.Write([]byte{':'})
+		h.Write([]byte(s))
+	}
+	write(text)
+	for _, p := range sorted {
+		write(p)
+	}
+	write(salt)
+
+	n := binary.BigEndian.Uint64(h.Sum(nil))
+	return int64(n%maxMessageID) + 1
+}
+
+// setDerivedID sets m.ID when WithDerivedID is used.
+func (m *message) setDerivedID() error {
+	if !m.DeriveID {
+		return nil
+	}
+	id := deriveID(m.Text, m.Phones, m.IDSalt)
+	if id < 1 || id > maxMessageID {
+		return ErrBadDerivedID
+	}
+	m.ID = id
+	return nil
+}
@@ -0,0 +1,58 @@
+package smsc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestDeriveID(t *testing.T) {
+	a := deriveID("test", []string{"1", "2"}, "salt")
+	if b := deriveID("test", []string{"2", "1"}, "salt"); a != b {
+		t.Errorf("phones order changes id: %d != %d", a, b)
+	}
+	for _, b := range []int64{
+		deriveID("test", []string{"1", "2"}, "other"),
+		deriveID("test", []string{"12"}, "salt"),
+		deriveID("test1", []string{"2"}, "salt"),
+	} {
+		if a == b {
+			t.Errorf("want a different id from %d", a)
+		}
+	}
+	if a < 1 || a > maxMessageID {
+		t.Errorf("id %d is out of range", a)
+	}
+}
+
+func TestClient_Send_derivedID(t *testing.T) {
+	var ids []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.PostFormValue("id"))
+		id, _ := strconv.ParseInt(r.PostFormValue("id"), 10, 64)
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Send("test", somePhone, WithDerivedID("order-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := deriveID("test", somePhone, "order-1")
+	if r.Meta.DerivedID != want || ids[0] != strconv.FormatInt(want, 10) {
+		t.Errorf("want id %d, got %d sent as %q", want, r.Meta.DerivedID, ids[0])
+	}
+
+	if _, err := c.Send("test", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	if ids[1] != "" {
+		t.Errorf("id: want none, got %q", ids[1])
+	}
+}
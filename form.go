@@ -0,0 +1,78 @@
+package smsc
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// field is a field of a request form.
+type field struct {
+	Key, Value string
+}
+
+// form is an ordered list of fields. Unlike url.Values, it needs no map, so it
+// is cheaper for a short form of a single-recipient send.
+type form []field
+
+// set replaces a value of key k with v or appends it.
+func (f form) set(k, v string) form {
+	for i := range f {
+		if f[i].Key == k {
+			f[i].Value = v
+			return f
+		}
+	}
+	return append(f, field{k, v})
+}
+
+// encode returns f in "URL encoded" form. The result is equal to Encode of
+// url.Values with the same fields: fields are sorted by key and values of a
+// key keep their order.
+func (f form) encode() string {
+	// Insertion sort is stable and is fast for a few fields.
+	for i := 1; i < len(f); i++ {
+		for j := i; j > 0 && f[j].Key < f[j-1].Key; j-- {
+			f[j], f[j-1] = f[j-1], f[j]
+		}
+	}
+
+	n := 0
+	for _, x := range f {
+		n += len(x.Key) + len(x.Value) + 2
+	}
+	var b strings.Builder
+	b.Grow(n)
+	for i, x := range f {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(x.Key))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(x.Value))
+	}
+	return b.String()
+}
+
+// formPool reuses forms of single-recipient sends.
+var formPool = sync.Pool{
+	New: func() interface{} {
+		f := make(form, 0, 16)
+		return &f
+	},
+}
+
+// encode returns a form for a request to API in "URL encoded" form. It is
+// equal to Values().Encode() and is used by sends to a single phone, which
+// are the most frequent, e.g. one-time passwords.
+func (m *message) encode() string {
+	p := formPool.Get().(*form)
+	f := m.appendFields((*p)[:0])
+	s := f.encode()
+	for i := range f {
+		f[i] = field{} // do not keep texts and passwords in the pool
+	}
+	*p = f[:0]
+	formPool.Put(p)
+	return s
+}
@@ -0,0 +1,63 @@
+package smsc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+var MessageEncodeTests = []message{
+	{Login: "me", Password: "pass", Text: "test", Phones: somePhone},
+	{Text: "привет & пока", Phones: somePhone, Sender: "Shop", Charset: charsetUTF8, Format: formatJSON},
+	{Text: "test", Phones: somePhone, PartnerID: "123", ID: 42, Cost: CostCountBalance, Err: Err},
+	{Text: "test", Phones: somePhone, Window: &window{9, 21, -3}, SendTime: time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC)},
+	{Text: "test", Phones: somePhone, Valid: &valid{1, 30}, Translit: Translit, Flash: Flash, CallbackURL: "https://example.com/?a=b"},
+	{Text: "test", Phones: somePhone, Viber: Viber, ViberImage: "https://example.com/a.png", Subject: "subj", ReceiptEmail: "me@example.com"},
+	{Text: "test", Phones: []string{"1", "2", "3"}},
+	{Text: "test"},
+}
+
+func TestMessage_encode(t *testing.T) {
+	for _, m := range MessageEncodeTests {
+		want := m.Values().Encode()
+		if s := m.encode(); s != want {
+			t.Errorf("want %q, got %q", want, s)
+		}
+	}
+}
+
+var benchMessage = message{
+	Login:    "test",
+	Password: "1a1dc91c907325c69271ddf0c944bc72",
+	Text:     "Your code is 123456",
+	Phones:   somePhone,
+	Sender:   "Shop",
+	Charset:  charsetUTF8,
+	Format:   formatJSON,
+}
+
+func BenchmarkMessage_Values_Encode(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = benchMessage.Values().Encode()
+	}
+}
+
+func BenchmarkMessage_encode(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = benchMessage.encode()
+	}
+}
+
+func BenchmarkMessage_Values_manyPhones(b *testing.B) {
+	m := benchMessage
+	m.Phones = make([]string, MaxRecipients)
+	for i := range m.Phones {
+		m.Phones[i] = fmt.Sprintf("+7916%07d", i)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = m.Values().Encode()
+	}
+}
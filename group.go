@@ -0,0 +1,29 @@
+package smsc
+
+import (
+	"context"
+	"net/url"
+)
+
+// Group is a contact group of the account address book.
+type Group struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"num"` // number of phones
+}
+
+// Groups returns contact groups of the account.
+func (c *Client) Groups(ctx context.Context) ([]Group, error) {
+	var gg []Group
+	if err := c.call(ctx, "phones.php", url.Values{"get_grp": []string{"1"}}, &gg); err != nil {
+		return nil, err
+	}
+	return gg, nil
+}
+
+// SendToGroup sends text to phones of a contact group named group. Groups are
+// managed in the account address book, see Groups.
+func (c *Client) SendToGroup(ctx context.Context, group, text string, opts ...Opt) (*Result, error) {
+	opts = append(opts[:len(opts):len(opts)], func(m *message) { m.Group = group })
+	return c.SendContext(ctx, text, nil, opts...)
+}
@@ -0,0 +1,73 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Groups(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/phones.php" || r.FormValue("get_grp") != "1" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Form)
+		}
+		w.Write([]byte(`[{"id":1,"name":"VIP","num":2},{"id":2,"name":"Staff","num":10}]`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gg, err := c.Groups(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Group{{1, "VIP", 2}, {2, "Staff", 10}}
+	if !reflect.DeepEqual(gg, want) {
+		t.Errorf("want %v, got %v", want, gg)
+	}
+}
+
+func TestClient_SendToGroup(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if g := r.PostForm.Get("group"); g != "VIP" {
+			t.Errorf("group: want VIP, got %q", g)
+		}
+		if p, ok := r.PostForm["phones"]; ok {
+			t.Errorf("unexpected phones %v", p)
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 2})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.SendToGroup(context.Background(), "VIP", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Count != 2 {
+		t.Errorf("count: want 2, got %d", r.Count)
+	}
+}
+
+func TestMessage_Validate_group(t *testing.T) {
+	m := message{Text: "test", Group: "VIP"}
+	if err := m.Validate(); err != nil {
+		t.Error(err)
+	}
+	WithLocalDeliveryWindow(9, 21)(&m)
+	want := &ConflictError{"group", "local delivery window"}
+	if err := m.Validate(); !reflect.DeepEqual(err, want) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+}
@@ -0,0 +1,147 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// historyPageSize is a maximum number of entries API returns at once.
+const historyPageSize = 1000
+
+// historyDateLayout is a date format of get.php period.
+const historyDateLayout = "02.01.2006"
+
+// HistoryEntry is a message sent from the account.
+type HistoryEntry struct {
+	ID     int64     `json:"id"`
+	Phone  string    `json:"phone"`
+	Text   string    `json:"message"`
+	Status Status    `json:"-"`
+	Cost   Money     `json:"cost"`
+	SentAt time.Time `json:"-"`
+}
+
+// historyJSON is a JSON form of HistoryEntry.
+type historyJSON struct {
+	historyFields
+	Status    int   `json:"status"`
+	Timestamp int64 `json:"send_timestamp"`
+}
+
+// historyFields has HistoryEntry fields without UnmarshalJSON method.
+type historyFields HistoryEntry
+
+func (e *HistoryEntry) UnmarshalJSON(b []byte) error {
+	var aux historyJSON
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*e = aux.entry()
+	return nil
+}
+
+// entry returns a HistoryEntry of aux. HistoryIter parses historyJSON and
+// converts it, so Config.StrictParsing applies to its fields.
+func (aux *historyJSON) entry() HistoryEntry {
+	e := HistoryEntry(aux.historyFields)
+	e.Status = ParseStatus(aux.Status)
+	if aux.Timestamp != 0 {
+		e.SentAt = time.Unix(aux.Timestamp, 0)
+	}
+	return e
+}
+
+// HistoryIter iterates over messages sent in a period. Pages are fetched
+// lazily, so a long period is not loaded into memory at once.
+//
+//	it := c.HistoryIterator(ctx, from, to)
+//	for it.Next() {
+//		fmt.Println(it.Entry().Phone)
+//	}
+//	err := it.Err()
+type HistoryIter struct {
+	c        *Client
+	ctx      context.Context
+	from, to time.Time
+
+	page   []HistoryEntry
+	i      int
+	prevID int64
+	done   bool
+	err    error
+}
+
+// HistoryIterator returns an iterator over messages sent from from to to.
+func (c *Client) HistoryIterator(ctx context.Context, from, to time.Time) *HistoryIter {
+	return &HistoryIter{c: c, ctx: ctx, from: from, to: to, i: -1}
+}
+
+// Next advances to the next entry. It returns false when entries are over or
+// an error occurred.
+func (it *HistoryIter) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.i+1 < len(it.page) {
+		it.i++
+		return true
+	}
+	if it.done {
+		return false
+	}
+	if err := it.fetch(); err != nil {
+		it.err = err
+		return false
+	}
+	if len(it.page) == 0 {
+		return false
+	}
+	it.i = 0
+	return true
+}
+
+// Entry returns the current entry.
+func (it *HistoryIter) Entry() HistoryEntry {
+	return it.page[it.i]
+}
+
+// Err returns an error which stopped the iteration.
+func (it *HistoryIter) Err() error {
+	return it.err
+}
+
+// fetch requests the next page of entries.
+func (it *HistoryIter) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+	v := url.Values{
+		"get_messages": []string{"1"},
+		"start":        []string{it.from.Format(historyDateLayout)},
+		"end":          []string{it.to.Format(historyDateLayout)},
+		"cnt":          []string{strconv.Itoa(historyPageSize)},
+	}
+	if it.prevID != 0 {
+		v.Set("prev_id", strconv.FormatInt(it.prevID, 10))
+	}
+
+	var aux []historyJSON
+	if err := it.c.call(it.ctx, "get.php", v, &aux); err != nil {
+		return err
+	}
+	page := make([]HistoryEntry, len(aux))
+	for i := range aux {
+		page[i] = aux[i].entry()
+	}
+	if len(page) < historyPageSize {
+		it.done = true
+	}
+	if len(page) > 0 {
+		it.prevID = page[len(page)-1].ID
+	}
+	it.page, it.i = page, -1
+	return nil
+}
@@ -0,0 +1,125 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// historyServer serves n history entries with IDs from 1 to n.
+func historyServer(t *testing.T, n int, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.URL.Path != "/get.php" {
+			t.Errorf("path: want %q, got %q", "/get.php", r.URL.Path)
+		}
+		if s := r.PostFormValue("get_messages"); s != "1" {
+			t.Errorf("get_messages: want 1, got %q", s)
+		}
+		if s := r.PostFormValue("start"); s != "01.01.2020" {
+			t.Errorf("start: want %q, got %q", "01.01.2020", s)
+		}
+		cnt, _ := strconv.Atoi(r.PostFormValue("cnt"))
+		prev, _ := strconv.Atoi(r.PostFormValue("prev_id"))
+
+		page := []map[string]interface{}{}
+		for id := prev + 1; id <= n && len(page) < cnt; id++ {
+			page = append(page, map[string]interface{}{
+				"id":             id,
+				"phone":          "79161234567",
+				"message":        "test",
+				"status":         1,
+				"cost":           "1.5",
+				"send_timestamp": 1577836800,
+			})
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+}
+
+func TestClient_HistoryIterator(t *testing.T) {
+	var requests int
+	n := historyPageSize + historyPageSize/2
+	ts := historyServer(t, n, &requests)
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := c.HistoryIterator(context.Background(), day, day.Add(24*time.Hour))
+	var got int
+	for it.Next() {
+		got++
+		e := it.Entry()
+		if e.ID != int64(got) {
+			t.Fatalf("id: want %d, got %d", got, e.ID)
+		}
+		if e.Status != StatusDelivered || e.Cost != 150 || !e.SentAt.Equal(day) {
+			t.Fatalf("unexpected entry %+v", e)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got != n {
+		t.Errorf("entries: want %d, got %d", n, got)
+	}
+	if requests != 2 {
+		t.Errorf("requests: want 2, got %d", requests)
+	}
+}
+
+func TestClient_HistoryIterator_canceled(t *testing.T) {
+	var requests int
+	ts := historyServer(t, historyPageSize+1, &requests)
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := c.HistoryIterator(ctx, day, day)
+	for i := 0; i < historyPageSize; i++ {
+		if !it.Next() {
+			t.Fatalf("%d: %v", i, it.Err())
+		}
+	}
+	cancel()
+	if it.Next() {
+		t.Error("want false, got true")
+	}
+	if err := it.Err(); err != context.Canceled {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+	if requests != 1 {
+		t.Errorf("requests: want 1, got %d", requests)
+	}
+}
+
+func TestClient_HistoryIterator_strict(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1, "status": 1, "bogus_field": 5}]`))
+	}))
+	defer ts.Close()
+
+	for _, strict := range []bool{false, true} {
+		c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", StrictParsing: strict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		it := c.HistoryIterator(context.Background(), day, day)
+		for it.Next() {
+		}
+		if _, ok := it.Err().(*ParseError); ok != strict {
+			t.Errorf("strict %v: want a *ParseError %v, got %v", strict, strict, it.Err())
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package smsc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Defaults of Config.HLRPrecheck.
+const (
+	DefaultHLRConcurrency = 4
+	DefaultHLRTTL         = 24 * time.Hour
+)
+
+// UnreachableError is returned by a send when HLR precheck dropped all phones.
+// It matches ErrNoPhones with errors.Is.
+type UnreachableError struct {
+	Unreachable []string
+}
+
+func (e *UnreachableError) Error() string {
+	return "smsc: all phones are unreachable"
+}
+
+func (e *UnreachableError) Is(target error) bool {
+	return target == ErrNoPhones
+}
+
+// reachability is a cached result of Reachable. Operator is also used by
+// Config.LeastCostRouting, so a phone is looked up once for both.
+type reachability struct {
+	Reachable bool
+	Operator  string
+	At        time.Time
+}
+
+// minHLRPrune is a number of phones of hlrCache before expired results are
+// dropped for the first time.
+const minHLRPrune = 64
+
+// hlrCache keeps results of Reachable by phones.
+type hlrCache struct {
+	mu     sync.Mutex
+	phones map[string]reachability
+	prune  int // number of phones when expired results are dropped
+}
+
+func (hc *hlrCache) get(phone string) (reachability, bool) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	r, ok := hc.phones[phone]
+	return r, ok
+}
+
+// set caches r of phone. Results older than ttl are dropped when the cache
+// doubles, as by MemoryDedupe.
+func (hc *hlrCache) set(phone string, r reachability, ttl time.Duration) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.phones == nil {
+		hc.phones = make(map[string]reachability)
+	}
+	if len(hc.phones) >= hc.prune {
+		for p, x := range hc.phones {
+			if !fresh(x.At, r.At, ttl) {
+				delete(hc.phones, p)
+			}
+		}
+		if hc.prune = 2 * len(hc.phones); hc.prune < minHLRPrune {
+			hc.prune = minHLRPrune
+		}
+	}
+	hc.phones[phone] = r
+}
+
+// lookup returns reachability of phone using a cached result when it is
+// fresh. A phone of unknown reachability is reachable.
+func (c *Client) lookup(ctx context.Context, phone string) (reachability, error) {
+	if r, ok := c.hlr.get(phone); ok && fresh(r.At, c.now(), c.hlrTTL) {
+		return r, nil
+	}
+	ok, info, err := c.Reachable(ctx, phone)
+	if err == ErrUnknownReachability {
+		ok, err = true, nil
+	}
+	if err != nil {
+		return reachability{}, err
+	}
+	r := reachability{Reachable: ok, At: c.now()}
+	if info != nil {
+		r.Operator = info.Operator
+	}
+	c.hlr.set(phone, r, c.hlrTTL)
+	return r, nil
+}
+
+// lookupAll returns results of lookup of phones and their errors. Phones are
+// looked up with up to c.hlrConcurrency requests at once. An error is
+// returned when ctx is done.
+func (c *Client) lookupAll(ctx context.Context, phones []string) ([]reachability, []error, error) {
+	results := make([]reachability, len(phones))
+	errs := make([]error, len(phones))
+
+	sem := make(chan struct{}, c.hlrConcurrency)
+	var wg sync.WaitGroup
+	for i := range phones {
+		if err := acquire(ctx, sem); err != nil {
+			wg.Wait()
+			return nil, nil, err
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = c.lookup(ctx, phones[i])
+		}(i)
+	}
+	wg.Wait()
+	return results, errs, nil
+}
+
+// precheck returns reachable and unreachable phones.
+func (c *Client) precheck(ctx context.Context, phones []string) (ok, dropped []string, err error) {
+	results, errs, err := c.lookupAll(ctx, phones)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, phone := range phones {
+		if errs[i] != nil {
+			return nil, nil, errs[i]
+		}
+		if results[i].Reachable {
+			ok = append(ok, phone)
+		} else {
+			dropped = append(dropped, phone)
+		}
+	}
+	return ok, dropped, nil
+}
@@ -0,0 +1,137 @@
+package smsc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Send_hlrPrecheck(t *testing.T) {
+	operators := map[string]string{
+		"+79161234567": "MTS",
+		"+79031234567": "",
+	}
+	var mu sync.Mutex
+	lookups := make(map[string]int)
+	var active, peak int32
+	var sent []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info.php" {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			phone := r.PostFormValue("phone")
+			mu.Lock()
+			lookups[phone]++
+			mu.Unlock()
+			op, ok := operators[phone]
+			if !ok {
+				json.NewEncoder(w).Encode(&Error{Code: CodePhone, Desc: "invalid number"})
+				return
+			}
+			json.NewEncoder(w).Encode(&PhoneInfo{Operator: op})
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		sent = r.PostForm["phones"]
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: len(sent)})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass", HLRPrecheck: true, HLRConcurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	phones := []string{"+79161234567", "+70000000001", "+79031234567", "+70000000002"}
+	for i := 0; i < 2; i++ {
+		r, err := c.Send("test", phones)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"+79161234567", "+79031234567"}; !reflect.DeepEqual(want, sent) {
+			t.Errorf("sent: want %v, got %v", want, sent)
+		}
+		if want := []string{"+70000000001", "+70000000002"}; !reflect.DeepEqual(want, r.Meta.Unreachable) {
+			t.Errorf("unreachable: want %v, got %v", want, r.Meta.Unreachable)
+		}
+	}
+	for _, p := range phones {
+		if lookups[p] != 1 {
+			t.Errorf("%s: want 1 lookup, got %d", p, lookups[p])
+		}
+	}
+	if peak > 2 {
+		t.Errorf("concurrency: want 2 at most, got %d", peak)
+	}
+}
+
+func TestClient_Send_hlrAllUnreachable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/info.php" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(&Error{Code: CodePhone, Desc: "invalid number"})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass", HLRPrecheck: true, HLRConcurrency: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	phones := []string{"+70000000001", "+70000000002"}
+	_, err = c.Send("test", phones)
+	if want := (&UnreachableError{phones}); !reflect.DeepEqual(want, err) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+	if !errors.Is(err, ErrNoPhones) {
+		t.Errorf("want %v to match %v", err, ErrNoPhones)
+	}
+}
+
+func TestHLRCache_prune(t *testing.T) {
+	var hc hlrCache
+	ttl := time.Hour
+	for i := 0; i < minHLRPrune; i++ {
+		hc.set(fmt.Sprint(i), reachability{Reachable: true, At: day}, ttl)
+	}
+	hc.set("new", reachability{Reachable: true, At: day.Add(ttl)}, ttl)
+	if len(hc.phones) != 1 {
+		t.Errorf("want expired phones dropped, got %d phones", len(hc.phones))
+	}
+	if _, ok := hc.get("new"); !ok {
+		t.Error("want a new phone kept")
+	}
+}
+
+func TestClient_Send_hlrInvalid(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass", HLRPrecheck: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", []string{"+79161234567", "+79031234567"}, With(Flash), With(BinHex)); err == nil {
+		t.Error("want an error of an invalid message")
+	}
+}
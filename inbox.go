@@ -0,0 +1,78 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// InboundMessage is a message received by the account.
+type InboundMessage struct {
+	ID   int64  `json:"id"`
+	From string `json:"phone"`    // phone of a sender
+	To   string `json:"to_phone"` // number of the account which received it
+	Text string `json:"message"`
+	Cost Money  `json:"cost"` // of paid numbers
+
+	ReceivedAt time.Time `json:"-"`
+}
+
+// inboundJSON is a JSON form of InboundMessage.
+type inboundJSON struct {
+	inboundFields
+	Timestamp int64 `json:"received"`
+}
+
+// inboundFields has InboundMessage fields without UnmarshalJSON method.
+type inboundFields InboundMessage
+
+func (m *InboundMessage) UnmarshalJSON(b []byte) error {
+	var aux inboundJSON
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*m = aux.message()
+	return nil
+}
+
+// message returns an InboundMessage of aux. Client methods parse inboundJSON
+// and convert it, so Config.StrictParsing applies to its fields.
+func (aux *inboundJSON) message() InboundMessage {
+	m := InboundMessage(aux.inboundFields)
+	if aux.Timestamp != 0 {
+		m.ReceivedAt = time.Unix(aux.Timestamp, 0)
+	}
+	return m
+}
+
+// Inbox returns messages received from from to to.
+func (c *Client) Inbox(ctx context.Context, from, to time.Time) ([]InboundMessage, error) {
+	return c.inbox(ctx, from, to, false)
+}
+
+// InboxUnread returns messages received from from to to which are not read
+// yet.
+func (c *Client) InboxUnread(ctx context.Context, from, to time.Time) ([]InboundMessage, error) {
+	return c.inbox(ctx, from, to, true)
+}
+
+func (c *Client) inbox(ctx context.Context, from, to time.Time, unread bool) ([]InboundMessage, error) {
+	v := url.Values{
+		"get_answers": []string{"1"},
+		"start":       []string{from.Format(historyDateLayout)},
+		"end":         []string{to.Format(historyDateLayout)},
+	}
+	if unread {
+		v.Set("unread", "1")
+	}
+	var aux []inboundJSON
+	if err := c.call(ctx, "get.php", v, &aux); err != nil {
+		return nil, err
+	}
+	mm := make([]InboundMessage, len(aux))
+	for i := range aux {
+		mm[i] = aux[i].message()
+	}
+	return mm, nil
+}
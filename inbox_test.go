@@ -0,0 +1,93 @@
+package smsc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var ClientInboxTests = []struct {
+	Name     string
+	Unread   bool
+	Body     string
+	Messages []InboundMessage
+	Err      error
+}{
+	{
+		"All",
+		false,
+		`[{"id": 1, "phone": "79161234567", "to_phone": "79000000000", "message": "STOP", "cost": "0", "received": 1577836800}]`,
+		[]InboundMessage{{ID: 1, From: "79161234567", To: "79000000000", Text: "STOP", ReceivedAt: day.Local()}},
+		nil,
+	},
+	{
+		"Unread",
+		true,
+		`[]`,
+		[]InboundMessage{},
+		nil,
+	},
+	{
+		"Error",
+		false,
+		`{"error": "authorise error", "error_code": 2}`,
+		nil,
+		&Error{Code: CodeAuth, Desc: "authorise error"},
+	},
+}
+
+func TestClient_Inbox(t *testing.T) {
+	for _, tt := range ClientInboxTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/get.php" {
+					t.Errorf("path: want %q, got %q", "/get.php", r.URL.Path)
+				}
+				if s := r.PostFormValue("get_answers"); s != "1" {
+					t.Errorf("get_answers: want 1, got %q", s)
+				}
+				if unread := r.PostFormValue("unread") == "1"; unread != tt.Unread {
+					t.Errorf("unread: want %v, got %v", tt.Unread, unread)
+				}
+				w.Write([]byte(tt.Body))
+			}))
+			defer ts.Close()
+
+			c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			inbox := c.Inbox
+			if tt.Unread {
+				inbox = c.InboxUnread
+			}
+			mm, err := inbox(context.Background(), day, day)
+			if !reflect.DeepEqual(tt.Err, err) {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+			if !reflect.DeepEqual(tt.Messages, mm) {
+				t.Errorf("want %+v, got %+v", tt.Messages, mm)
+			}
+		})
+	}
+}
+
+func TestClient_Inbox_strict(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1, "phone": "79161234567", "bogus_field": 5}]`))
+	}))
+	defer ts.Close()
+
+	for _, strict := range []bool{false, true} {
+		c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", StrictParsing: strict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.Inbox(context.Background(), day, day)
+		if _, ok := err.(*ParseError); ok != strict {
+			t.Errorf("strict %v: want a *ParseError %v, got %v", strict, strict, err)
+		}
+	}
+}
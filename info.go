@@ -0,0 +1,52 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// ErrUnknownReachability is returned by Reachable when API knows no operator
+// of a phone, so it may be either reachable or not.
+var ErrUnknownReachability = errors.New("smsc: unknown phone reachability")
+
+// PhoneInfo describes a phone operator.
+type PhoneInfo struct {
+	Country  string `json:"country"`
+	Operator string `json:"operator"`
+	Region   string `json:"region"`
+	Mccmnc   string `json:"mccmnc"`
+}
+
+// Reachable tells whether phone is served by an operator. It uses a cheap
+// number info lookup instead of HLR request.
+//
+// An invalid phone is false with no error. If API knows no operator of a
+// valid phone, it is false with ErrUnknownReachability.
+func (c *Client) Reachable(ctx context.Context, phone string) (bool, *PhoneInfo, error) {
+	info, err := c.phoneInfo(ctx, phone)
+	if err != nil {
+		var e *Error
+		if errors.As(err, &e) && e.Code == CodePhone {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	if info.Operator == "" {
+		return false, info, ErrUnknownReachability
+	}
+	return true, info, nil
+}
+
+// phoneInfo returns an operator of phone.
+func (c *Client) phoneInfo(ctx context.Context, phone string) (*PhoneInfo, error) {
+	v := url.Values{
+		"get_operator": []string{"1"},
+		"phone":        []string{phone},
+	}
+	var info PhoneInfo
+	if err := c.call(ctx, "info.php", v, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
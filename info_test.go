@@ -0,0 +1,80 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var ClientReachableTests = []struct {
+	Name      string
+	Value     interface{}
+	Reachable bool
+	Info      *PhoneInfo
+	Err       error
+}{
+	{
+		"Reachable",
+		&PhoneInfo{Country: "Russia", Operator: "MTS", Mccmnc: "25001"},
+		true,
+		&PhoneInfo{Country: "Russia", Operator: "MTS", Mccmnc: "25001"},
+		nil,
+	},
+	{
+		"Unknown",
+		&PhoneInfo{Country: "Russia"},
+		false,
+		&PhoneInfo{Country: "Russia"},
+		ErrUnknownReachability,
+	},
+	{
+		"Invalid",
+		&Error{Code: CodePhone, Desc: "invalid number"},
+		false,
+		nil,
+		nil,
+	},
+	{
+		"Error",
+		&Error{Code: CodeAuth, Desc: "authorise error"},
+		false,
+		nil,
+		&Error{Code: CodeAuth, Desc: "authorise error"},
+	},
+}
+
+func TestClient_Reachable(t *testing.T) {
+	for _, tt := range ClientReachableTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/info.php" {
+					t.Errorf("path: want %q, got %q", "/info.php", r.URL.Path)
+				}
+				if s := r.PostFormValue("phone"); s != "+79161234567" {
+					t.Errorf("phone: got %q", s)
+				}
+				json.NewEncoder(w).Encode(tt.Value)
+			}))
+			defer ts.Close()
+
+			c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ok, info, err := c.Reachable(context.Background(), "+79161234567")
+			if ok != tt.Reachable {
+				t.Errorf("reachable: want %v, got %v", tt.Reachable, ok)
+			}
+			if !reflect.DeepEqual(tt.Info, info) {
+				t.Errorf("info: want %v, got %v", tt.Info, info)
+			}
+			if !reflect.DeepEqual(tt.Err, err) {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+		})
+	}
+}
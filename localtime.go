@@ -0,0 +1,65 @@
+package smsc
+
+import "context"
+
+// tzOfPhone returns a timezone of phone by t relative to Moscow in hours.
+func tzOfPhone(t PrefixTable, phone string) int {
+	iso, ok := t.lookup(phone)
+	if !ok {
+		return 0
+	}
+	return t[iso].Offset - mskOffset
+}
+
+// localBatches groups m.Phones by timezones told by t and sets Window of each
+// group by m.LocalWindow. Groups keep the order of phones.
+func (m *message) localBatches(t PrefixTable) []*message {
+	var batches []*message
+	byTZ := make(map[int]*message)
+	for _, phone := range m.Phones {
+		tz := tzOfPhone(t, phone)
+		b, ok := byTZ[tz]
+		if !ok {
+			p := *m
+			p.Window = &window{From: m.LocalWindow.From, To: m.LocalWindow.To, TZ: tz}
+			p.LocalWindow = nil
+			p.Phones = nil
+			b = &p
+			byTZ[tz] = b
+			batches = append(batches, b)
+		}
+		b.Phones = append(b.Phones, phone)
+	}
+	return batches
+}
+
+// sendLocal sends m.localBatches one by one and merges their Results. When a
+// batch fails, Results of the sent ones are returned with a *PartialError.
+func (c *Client) sendLocal(ctx context.Context, m *message, routed bool) (*Result, error) {
+	batches := m.localBatches(c.prefixes)
+	rr := make([]*Result, 0, len(batches))
+	for i, b := range batches {
+		var r *Result
+		var err error
+		if routed {
+			r, err = c.sendRouted(ctx, b)
+		} else {
+			r, err = c.post(ctx, b)
+		}
+		if err != nil {
+			if r != nil { // some routes of b were sent
+				rr = append(rr, r)
+			}
+			unsent := unsentPhones(err, b.Phones)
+			for _, b := range batches[i+1:] {
+				unsent = append(unsent, b.Phones...)
+			}
+			return partial(rr, mergeBatches, i, len(batches), unsent, err)
+		}
+		rr = append(rr, r)
+	}
+	if len(rr) == 1 {
+		return rr[0], nil
+	}
+	return mergeBatches(rr), nil
+}
@@ -0,0 +1,109 @@
+package smsc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var TZOfPhoneTests = []struct {
+	Phone string
+	TZ    int
+}{
+	{"+79161234567", 0},
+	{"+77011234567", 2},
+	{"+375291234567", 0},
+	{"+380501234567", -1},
+	{"+996555123456", 3},
+	{"+11234567890", 0},
+}
+
+func TestTZOfPhone(t *testing.T) {
+	for _, tt := range TZOfPhoneTests {
+		t.Run(tt.Phone, func(t *testing.T) {
+			if tz := tzOfPhone(countries, tt.Phone); tz != tt.TZ {
+				t.Errorf("want %d, got %d", tt.TZ, tz)
+			}
+		})
+	}
+}
+
+func TestWithLocalDeliveryWindow_panics(t *testing.T) {
+	for _, w := range [][2]int{{-1, 10}, {10, 25}, {10, 10}, {21, 9}} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrBadWindow {
+					t.Errorf("%v: want %v, got %v", w, ErrBadWindow, r)
+				}
+			}()
+			WithLocalDeliveryWindow(w[0], w[1])
+		}()
+	}
+}
+
+func TestClient_Send_localDeliveryWindow(t *testing.T) {
+	type batch struct {
+		Time, TZ string
+		Phones   []string
+	}
+	var batches []batch
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, batch{r.PostForm.Get("time"), r.PostForm.Get("tz"), r.PostForm["phones"]})
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: len(r.PostForm["phones"])})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	phones := []string{"+79161234567", "+77011234567", "+375291234567"}
+	r, err := c.Send("test", phones, WithLocalDeliveryWindow(9, 21))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []batch{
+		{"9-21", "0", []string{"+79161234567", "+375291234567"}},
+		{"9-21", "2", []string{"+77011234567"}},
+	}
+	if !reflect.DeepEqual(want, batches) {
+		t.Errorf("want %v, got %v", want, batches)
+	}
+	if r.Count != len(phones) || len(r.Parts) != 2 {
+		t.Errorf("unexpected result %v with %d parts", r, len(r.Parts))
+	}
+}
+
+func TestClient_Send_localDeliveryWindowPartial(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("tz") != "0" {
+			w.Write([]byte(`{"error": "invalid number", "error_code": 7}`))
+			return
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 2})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	phones := []string{"+79161234567", "+77011234567", "+375291234567"}
+	r, err := c.Send("test", phones, WithLocalDeliveryWindow(9, 21))
+	var perr *PartialError
+	if !errors.As(err, &perr) {
+		t.Fatalf("want *PartialError, got %v", err)
+	}
+	if perr.Sent != 1 || perr.Total != 2 {
+		t.Errorf("want 1 of 2 sent, got %d of %d", perr.Sent, perr.Total)
+	}
+	if r == nil || r.Count != 2 || r.ID != id {
+		t.Errorf("want a Result of the sent batch, got %v", r)
+	}
+}
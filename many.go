@@ -0,0 +1,93 @@
+package smsc
+
+import (
+	"context"
+	"sync"
+)
+
+// OutgoingMessage is a message of SendMany.
+type OutgoingMessage struct {
+	Text   string
+	Phones []string
+	Opts   []Opt
+}
+
+// SendOutcome pairs an OutgoingMessage with a Result or an error of its send.
+type SendOutcome struct {
+	Message OutgoingMessage
+	Result  *Result
+	Err     error
+}
+
+// SendMany sends msgs independently with up to concurrency requests at once.
+//
+// Outcomes are in order of msgs. When ctx is done, remaining messages are not
+// sent and their outcomes have ctx.Err().
+func (c *Client) SendMany(ctx context.Context, msgs []OutgoingMessage, concurrency int) *AggregateResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	out := make([]SendOutcome, len(msgs))
+	for i, msg := range msgs {
+		out[i].Message = msg
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := range msgs {
+		if err := acquire(ctx, sem); err != nil {
+			for j := i; j < len(msgs); j++ {
+				out[j].Err = err
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(o *SendOutcome) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			o.Result, o.Err = c.SendContext(ctx, o.Message.Text, o.Message.Phones, o.Message.Opts...)
+		}(&out[i])
+	}
+
+	wg.Wait()
+	return aggregate(out)
+}
+
+// SendChunked sends text to phones by chunks of up to size phones one after
+// another. It allows to send to more phones than a single request accepts.
+// MaxRecipients is used when size is not positive or is greater.
+func (c *Client) SendChunked(ctx context.Context, text string, phones []string, size int, opts ...Opt) *AggregateResult {
+	if size < 1 || size > MaxRecipients {
+		size = MaxRecipients
+	}
+	// A send without phones fails as SendContext does, e.g. with ErrNoPhones.
+	msgs := []OutgoingMessage{{Text: text, Opts: opts}}
+	if len(phones) > 0 {
+		msgs = nil
+	}
+	for len(phones) > 0 {
+		n := size
+		if n > len(phones) {
+			n = len(phones)
+		}
+		msgs = append(msgs, OutgoingMessage{Text: text, Phones: phones[:n:n], Opts: opts})
+		phones = phones[n:]
+	}
+	return c.SendMany(ctx, msgs, 1)
+}
+
+// acquire takes a slot of sem unless ctx is done.
+func acquire(ctx context.Context, sem chan struct{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
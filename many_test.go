@@ -0,0 +1,158 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_SendMany(t *testing.T) {
+	var active, peak int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if r.PostFormValue("mes") == "bad" {
+			json.NewEncoder(w).Encode(&Error{Code: CodePhone, Desc: "invalid number"})
+			return
+		}
+		id, _ := strconv.ParseInt(r.PostFormValue("mes"), 10, 64)
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := []OutgoingMessage{
+		{Text: "1", Phones: somePhone},
+		{Text: "bad", Phones: somePhone},
+		{Text: "3", Phones: somePhone},
+		{Text: "4", Phones: somePhone},
+		{Text: "5", Phones: somePhone},
+	}
+	a := c.SendMany(context.Background(), msgs, 2)
+	out := a.Outcomes
+
+	if len(out) != len(msgs) {
+		t.Fatalf("want %d outcomes, got %d", len(msgs), len(out))
+	}
+	for i, o := range out {
+		if o.Message.Text != msgs[i].Text {
+			t.Errorf("%d: want message %q, got %q", i, msgs[i].Text, o.Message.Text)
+		}
+		if o.Message.Text == "bad" {
+			if o.Err == nil {
+				t.Errorf("%d: want error, got nil", i)
+			}
+			continue
+		}
+		if o.Err != nil {
+			t.Errorf("%d: %v", i, o.Err)
+		} else if id := strconv.FormatInt(o.Result.ID, 10); id != o.Message.Text {
+			t.Errorf("%d: want id %s, got %s", i, o.Message.Text, id)
+		}
+	}
+	if a.TotalCount != 4 || a.Failed() != 1 || len(a.FailedPhones) != 1 {
+		t.Errorf("unexpected aggregate: %s", a.Summary())
+	}
+	if peak > 2 {
+		t.Errorf("concurrency: want 2 at most, got %d", peak)
+	}
+}
+
+func TestClient_SendMany_cancelled(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out := c.SendMany(ctx, []OutgoingMessage{{Text: "1", Phones: somePhone}}, 1).Outcomes
+	if out[0].Err != context.Canceled {
+		t.Errorf("want %v, got %v", context.Canceled, out[0].Err)
+	}
+}
+
+func TestClient_SendChunked(t *testing.T) {
+	var chunks [][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, r.PostForm["phones"])
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: len(r.PostForm["phones"])})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	phones := []string{"1", "2", "3", "4", "5"}
+	a := c.SendChunked(context.Background(), "test", phones, 2)
+	if err := a.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"1", "2"}, {"3", "4"}, {"5"}}
+	if !reflect.DeepEqual(want, chunks) {
+		t.Errorf("want %v, got %v", want, chunks)
+	}
+	if a.TotalCount != len(phones) {
+		t.Errorf("count: want %d, got %d", len(phones), a.TotalCount)
+	}
+}
+
+func TestClient_SendChunked_partial(t *testing.T) {
+	var n int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n++; n > 1 {
+			w.Write([]byte(`{"error": "invalid number", "error_code": 7}`))
+			return
+		}
+		w.Write([]byte(`{"id": 1, "cnt": 1, "cost": "1.50"}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := Generate(abc+" ", 2*smsLatinChars)
+	a := c.SendChunked(context.Background(), text, somePhone, 0, WithAutoSplit(smsLatinChars))
+	if a.TotalCount != 1 || a.TotalCost != 150 {
+		t.Errorf("want 1 SMS of 1.50, got %d of %s", a.TotalCount, a.TotalCost)
+	}
+	if len(a.FailedPhones) != 0 {
+		t.Errorf("want no failed phones, got %v", a.FailedPhones)
+	}
+}
+
+func TestClient_SendChunked_noPhones(t *testing.T) {
+	c, err := New(Config{URL: "http://localhost:0", Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := c.SendChunked(context.Background(), "test", nil, 2)
+	if err := a.Err(); !errors.Is(err, ErrNoPhones) {
+		t.Errorf("want %v, got %v", ErrNoPhones, err)
+	}
+}
@@ -1,32 +1,178 @@
 package smsc
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/mail"
 	"net/url"
+	"strconv"
+	"time"
 	"unicode/utf8"
 )
 
 var (
-	ErrLongText = errors.New("smsc: too long text to send")
-	ErrNoPhones = errors.New("smsc: empty phones list")
+	ErrLongText           = errors.New("smsc: too long text to send")
+	ErrNoPhones           = errors.New("smsc: empty phones list")
+	ErrManyAttachments    = errors.New("smsc: too many attachments")
+	ErrLargeAttachments   = errors.New("smsc: too large attachments")
+	ErrAttachmentsChannel = errors.New("smsc: attachments require mail or mms")
+	ErrBadReceiptEmail    = errors.New("smsc: invalid receipt email")
+	ErrNoPlainPassword    = errors.New("smsc: plain password is not configured")
 )
 
+// Message is a message built with MessageBuilder for Client.SendMessage. It
+// is also a view of a message for Config.PreSend hook.
+type Message struct {
+	Text   string
+	Phones []string
+	Sender string
+
+	opts []Opt
+}
+
+// MessageBuilder builds a Message, e.g.
+//
+//	NewMessage(text, phones).Sender("Shop").Flash().Build()
+type MessageBuilder struct {
+	m Message
+}
+
+// NewMessage returns a builder of a message with text to phones.
+func NewMessage(text string, phones []string) *MessageBuilder {
+	return &MessageBuilder{m: Message{Text: text, Phones: phones}}
+}
+
+// Sender sets the author of a message. See Sender option.
+func (b *MessageBuilder) Sender(s string) *MessageBuilder {
+	b.m.Sender = s
+	return b
+}
+
+// Flash makes a message a flash SMS.
+func (b *MessageBuilder) Flash() *MessageBuilder {
+	return b.Opts(With(Flash))
+}
+
+// Opts adds options to a message.
+func (b *MessageBuilder) Opts(opts ...Opt) *MessageBuilder {
+	b.m.opts = append(b.m.opts, opts...)
+	return b
+}
+
+// Build returns a Message. The builder may be used further, the Message is not
+// affected then.
+func (b *MessageBuilder) Build() *Message {
+	m := b.m
+	m.Phones = append([]string(nil), b.m.Phones...)
+	m.opts = append([]Opt(nil), b.m.opts...)
+	return &m
+}
+
+// options returns options which make m.
+func (m *Message) options() []Opt {
+	opts := m.opts
+	if m.Sender != "" {
+		opts = append(opts[:len(opts):len(opts)], Sender(m.Sender))
+	}
+	return opts
+}
+
 // message controls what and how will sent to API.
 type message struct {
-	Login    string
-	Password string
-	Text     string
-	Phones   []string
-	Charset  charset
-	Format   format
-	Cost     Cost
-	Op       OpOpt
-	Err      ErrOpt
-	Valid    *valid
-	Sender   string
-	Translit TranslitOpt
+	Login     string
+	Password  string
+	PartnerID string
+	Text      string
+	Phones    []string
+	Charset   charset
+	Format    format
+	Cost      Cost
+	Op        OpOpt
+	Err       ErrOpt
+	Valid     *valid
+	// ExpiresAt is converted to Valid before sending.
+	ExpiresAt time.Time
+	Window    *window
+	// LocalWindow is converted to Window of each recipient country.
+	LocalWindow *window
+	SendTime    time.Time
+	Sender      string
+	Translit    TranslitOpt
+	Flash       FlashOpt
+	Bin         BinOpt
+	Viber       ViberOpt
+	ViberImage  string
+	ViberButton *viberButton
+	MMS         MMSOpt
+	Mail        MailOpt
+	Call        CallOpt
+
+	CallbackURL  string
+	Subject      string
+	ReceiptEmail string
+
+	// Group is a name of a contact group which receives a message besides
+	// Phones.
+	Group string
+
+	// PhonesFile is uploaded as a file instead of Phones when set.
+	PhonesFile  io.Reader
+	Attachments []Attachment
+
+	// Timeout limits a request duration when positive. It is not sent.
+	Timeout time.Duration
+	// Transactional messages ignore quiet hours. It is not sent.
+	Transactional bool
+	// Dedup removes duplicate phones. It is not sent.
+	Dedup bool
+	// ID is sent when positive. DeriveID sets it from IDSalt.
+	ID       int64
+	DeriveID bool
+	IDSalt   string
+	// Force skips Validate. It is not sent.
+	Force bool
+	// RetryBudget limits retries of the send when set. It is not sent.
+	RetryBudget *RetryBudget
+	// PlainPassword sends Config.Password instead of its hash.
+	PlainPassword bool
+	// AutoSplit splits a text into separate messages of up to AutoSplit
+	// characters.
+	AutoSplit int
+	// ConcatRef splits a binary message into parts with UDH.
+	ConcatRef *uint8
+
+	Channels       []Channel
+	ChannelSenders map[Channel]string
+	ChannelValid   map[Channel]*valid
+}
+
+// Attachment is a file sent with e-mail or MMS.
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+// MaxRecipients is a maximum number of phones API accepts in one request. Use
+// SendChunked to send to more phones.
+const MaxRecipients = 1000
+
+// TooManyRecipientsError is returned when a message has more than Max phones.
+type TooManyRecipientsError struct {
+	Count, Max int
+}
+
+func (e *TooManyRecipientsError) Error() string {
+	return fmt.Sprintf("smsc: %d recipients exceed %d per request, use SendChunked", e.Count, e.Max)
 }
 
+const (
+	attachmentsMax     = 10
+	attachmentsMaxSize = 5 << 20
+)
+
 const (
 	smsMax        = 5
 	smsSize       = 160
@@ -34,15 +180,167 @@ const (
 	smsHeaderSize = 7
 )
 
+// sendTime returns a time when m will be sent if it is sent at now.
+func (m *message) sendTime(now time.Time) time.Time {
+	if !m.SendTime.IsZero() {
+		return m.SendTime
+	}
+	return now
+}
+
+// context returns ctx limited by m.Timeout.
+func (m *message) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.Timeout > 0 {
+		return context.WithTimeout(ctx, m.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
 // Validate checks the message integrity and returns an optional error.
 func (m *message) Validate() error {
-	if n := CountBytes(m.Text); n > smsMaxSize {
-		return ErrLongText
+	if err := m.validateConflicts(); err != nil {
+		return err
+	}
+	if err := m.validateLength(); err != nil {
+		return err
+	}
+	if err := m.validateConcat(); err != nil {
+		return err
+	}
+	if err := m.validateChannels(); err != nil {
+		return err
+	}
+	if err := m.validateViber(); err != nil {
+		return err
+	}
+	if m.ReceiptEmail != "" {
+		if a, err := mail.ParseAddress(m.ReceiptEmail); err != nil || a.Name != "" {
+			return ErrBadReceiptEmail
+		}
 	}
-	if len(m.Phones) == 0 {
+	if len(m.Phones) == 0 && m.PhonesFile == nil && m.Group == "" {
 		return ErrNoPhones
 	}
-	// TODO: Validate options when added.
+	if len(m.Phones) > MaxRecipients {
+		return &TooManyRecipientsError{Count: len(m.Phones), Max: MaxRecipients}
+	}
+	return m.validateAttachments()
+}
+
+// validateAttachments checks count and total size of m.Attachments.
+func (m *message) validateAttachments() error {
+	if len(m.Attachments) == 0 {
+		return nil
+	}
+	if ch := m.channel(); ch != ChannelMail && ch != ChannelMMS {
+		return ErrAttachmentsChannel
+	}
+	if len(m.Attachments) > attachmentsMax {
+		return ErrManyAttachments
+	}
+	var size int
+	for _, a := range m.Attachments {
+		size += len(a.Data)
+	}
+	if size > attachmentsMaxSize {
+		return ErrLargeAttachments
+	}
+	return nil
+}
+
+// ConflictError is returned when a message has options which cannot be
+// combined.
+type ConflictError struct {
+	A, B string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("smsc: %s cannot be combined with %s", e.A, e.B)
+}
+
+// flag tells whether an option is set on a message.
+type flag struct {
+	Name  string
+	IsSet func(*message) bool
+}
+
+// exclusiveFlags lists groups of options. Options of one group are mutually
+// exclusive.
+var exclusiveFlags = [][]flag{
+	// Message kinds.
+	{
+		{"flash", func(m *message) bool { return m.Flash != 0 }},
+		{"binary", func(m *message) bool { return m.Bin != 0 }},
+		{"viber", func(m *message) bool { return m.Viber != 0 }},
+		{"mms", func(m *message) bool { return m.MMS != 0 }},
+		{"mail", func(m *message) bool { return m.Mail != 0 }},
+		{"call", func(m *message) bool { return m.Call != 0 }},
+	},
+	// Both are sent as valid.
+	{
+		{"valid", func(m *message) bool { return m.Valid != nil }},
+		{"expiry time", func(m *message) bool { return !m.ExpiresAt.IsZero() }},
+	},
+	// All are sent as time.
+	{
+		{"send time", func(m *message) bool { return !m.SendTime.IsZero() }},
+		{"delivery window", func(m *message) bool { return m.Window != nil }},
+		{"local delivery window", func(m *message) bool { return m.LocalWindow != nil }},
+	},
+	// Both split a message into separate requests.
+	{
+		{"concat ref", func(m *message) bool { return m.ConcatRef != nil }},
+		{"local delivery window", func(m *message) bool { return m.LocalWindow != nil }},
+	},
+	// Phones of a group are not known to split them by timezones.
+	{
+		{"group", func(m *message) bool { return m.Group != "" }},
+		{"local delivery window", func(m *message) bool { return m.LocalWindow != nil }},
+	},
+	// A kind sends by a single channel.
+	{
+		{"fallback channels", func(m *message) bool { return m.Channels != nil }},
+		{"viber", func(m *message) bool { return m.Viber != 0 }},
+	},
+	{
+		{"fallback channels", func(m *message) bool { return m.Channels != nil }},
+		{"mms", func(m *message) bool { return m.MMS != 0 }},
+	},
+	{
+		{"fallback channels", func(m *message) bool { return m.Channels != nil }},
+		{"mail", func(m *message) bool { return m.Mail != 0 }},
+	},
+	{
+		{"fallback channels", func(m *message) bool { return m.Channels != nil }},
+		{"call", func(m *message) bool { return m.Call != 0 }},
+	},
+	// A split text is not a binary.
+	{
+		{"auto split", func(m *message) bool { return m.AutoSplit > 0 }},
+		{"binary", func(m *message) bool { return m.Bin != 0 }},
+	},
+	// Binary data cannot be transliterated.
+	{
+		{"translit", func(m *message) bool { return m.Translit != 0 }},
+		{"binary", func(m *message) bool { return m.Bin != 0 }},
+	},
+}
+
+// validateConflicts returns a *ConflictError for the first pair of mutually
+// exclusive options set on m.
+func (m *message) validateConflicts() error {
+	for _, group := range exclusiveFlags {
+		var set *flag
+		for i := range group {
+			if !group[i].IsSet(m) {
+				continue
+			}
+			if set != nil {
+				return &ConflictError{A: set.Name, B: group[i].Name}
+			}
+			set = &group[i]
+		}
+	}
 	return nil
 }
 
@@ -78,36 +376,137 @@ func CountBytes(text string) int {
 
 // Values returns a form for a request to API.
 func (m *message) Values() url.Values {
-	v := url.Values{
-		"login":  []string{m.Login},
-		"psw":    []string{m.Password},
-		"mes":    []string{m.Text},
-		"phones": m.Phones,
+	v := url.Values{"phones": m.Phones}
+	for _, f := range m.appendOptions(nil) {
+		v[f.Key] = append(v[f.Key], f.Value)
 	}
+	return v
+}
 
+// appendFields appends fields of a form for a request to API to f.
+func (m *message) appendFields(f form) form {
+	f = m.appendOptions(f)
+	for _, p := range m.Phones {
+		f = append(f, field{"phones", p})
+	}
+	return f
+}
+
+// appendOptions appends fields of a form except phones to f. Phones are not
+// in f yet, so form.set scans only a few fields.
+func (m *message) appendOptions(f form) form {
+	f = append(f, field{"login", m.Login}, field{"psw", m.Password}, field{"mes", m.viberText()})
+
+	if m.PartnerID != "" {
+		f = f.set("pp", m.PartnerID)
+	}
+	if m.ID > 0 {
+		f = f.set("id", strconv.FormatInt(m.ID, 10))
+	}
 	if m.Charset != "" {
-		v.Set("charset", formatOpt(m.Charset))
+		f = f.set("charset", formatOpt(m.Charset))
 	}
 	if m.Format != 0 {
-		v.Set("fmt", formatOpt(m.Format))
+		f = f.set("fmt", formatOpt(m.Format))
 	}
 	if m.Cost != 0 {
-		v.Set("cost", formatOpt(m.Cost))
+		f = f.set("cost", formatOpt(m.Cost))
 	}
 	if m.Op != 0 {
-		v.Set("op", formatOpt(m.Op))
+		f = f.set("op", formatOpt(m.Op))
 	}
 	if m.Err != 0 {
-		v.Set("err", formatOpt(m.Err))
+		f = f.set("err", formatOpt(m.Err))
 	}
 	if m.Valid != nil {
-		v.Set("valid", formatOpt(m.Valid))
+		f = f.set("valid", formatOpt(m.Valid))
+	}
+	if m.Window != nil {
+		f = f.set("time", formatOpt(m.Window))
+		f = f.set("tz", formatOpt(m.Window.TZ))
+	}
+	if !m.SendTime.IsZero() {
+		f = f.set("time", m.SendTime.Format(sendTimeLayout))
+		f = f.set("tz", formatOpt(tzOf(m.SendTime)))
+	}
+	if m.Group != "" {
+		f = f.set("group", m.Group)
 	}
 	if m.Sender != "" {
-		v.Set("sender", formatOpt(m.Sender))
+		f = f.set("sender", formatOpt(m.Sender))
 	}
 	if m.Translit != 0 {
-		v.Set("translit", formatOpt(m.Translit))
+		f = f.set("translit", formatOpt(m.Translit))
 	}
-	return v
+	if len(m.Channels) > 0 {
+		channels, senders := m.channelsValues()
+		f = f.set("channels", channels)
+		if senders != "" {
+			f = f.set("channel_senders", senders)
+		}
+		if s := m.channelValidValues(); s != "" {
+			f = f.set("channel_valid", s)
+		}
+	}
+	if m.Subject != "" {
+		f = f.set("subj", m.Subject)
+	}
+	if m.ReceiptEmail != "" {
+		f = f.set("receipt_email", m.ReceiptEmail)
+	}
+	if m.CallbackURL != "" {
+		f = f.set("callback", m.CallbackURL)
+	}
+	if m.Flash != 0 {
+		f = f.set("flash", formatOpt(m.Flash))
+	}
+	if m.Bin != 0 {
+		f = f.set("bin", formatOpt(m.Bin))
+	}
+	if m.Viber != 0 {
+		f = f.set("viber", formatOpt(m.Viber))
+	}
+	if m.MMS != 0 {
+		f = f.set("mms", formatOpt(m.MMS))
+	}
+	if m.Mail != 0 {
+		f = f.set("mail", formatOpt(m.Mail))
+	}
+	if m.Call != 0 {
+		f = f.set("call", formatOpt(m.Call))
+	}
+	return f
+}
+
+// phonesFileField is a form field name for PhonesFile.
+const phonesFileField = "file"
+
+// WriteMultipart writes a multipart form for a request to API.
+func (m *message) WriteMultipart(w *multipart.Writer) error {
+	for k, vs := range m.Values() {
+		for _, v := range vs {
+			if err := w.WriteField(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	if m.PhonesFile != nil {
+		fw, err := w.CreateFormFile(phonesFileField, "phones.txt")
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(fw, m.PhonesFile); err != nil {
+			return err
+		}
+	}
+	for i, a := range m.Attachments {
+		fw, err := w.CreateFormFile(fmt.Sprintf("file%d", i+1), a.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(a.Data); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -1,9 +1,12 @@
 package smsc
 
 import (
+	"fmt"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -83,6 +86,49 @@ var MessageValidateTests = []struct {
 		message{Phones: []string{}, Text: "test"},
 		ErrNoPhones,
 	},
+	{
+		"Attachments are sent by mail",
+		message{Text: "test", Phones: somePhone, Mail: Mail, Attachments: []Attachment{{"a.pdf", []byte("a")}}},
+		nil,
+	},
+	{
+		"Attachments are not sent by SMS",
+		message{Text: "test", Phones: somePhone, Attachments: []Attachment{{"a.pdf", []byte("a")}}},
+		ErrAttachmentsChannel,
+	},
+	{
+		"Too many attachments",
+		message{Text: "test", Phones: somePhone, Mail: Mail, Attachments: make([]Attachment, attachmentsMax+1)},
+		ErrManyAttachments,
+	},
+	{
+		"Too large attachments",
+		message{Text: "test", Phones: somePhone, Mail: Mail, Attachments: []Attachment{
+			{"a.pdf", make([]byte, attachmentsMaxSize/2)},
+			{"b.pdf", make([]byte, attachmentsMaxSize/2+1)},
+		}},
+		ErrLargeAttachments,
+	},
+	{
+		"Receipt email is an address",
+		message{Text: "test", Phones: somePhone, ReceiptEmail: "me@example.com"},
+		nil,
+	},
+	{
+		"Receipt email is malformed",
+		message{Text: "test", Phones: somePhone, ReceiptEmail: "me@"},
+		ErrBadReceiptEmail,
+	},
+	{
+		"Receipt email has a name",
+		message{Text: "test", Phones: somePhone, ReceiptEmail: "Me <me@example.com>"},
+		ErrBadReceiptEmail,
+	},
+	{
+		"Phones file replaces phones",
+		message{Text: "test", PhonesFile: strings.NewReader("+71234567890")},
+		nil,
+	},
 }
 
 func TestMessage_Validate(t *testing.T) {
@@ -95,10 +141,77 @@ func TestMessage_Validate(t *testing.T) {
 	}
 }
 
+var MessageValidateConflictsTests = []struct {
+	Opt Opt
+	Err error
+}{
+	{With(Flash), nil},
+	{With(Flash, Translit), nil},
+	{With(Flash, Bin), &ConflictError{"flash", "binary"}},
+	{With(Flash, Viber), &ConflictError{"flash", "viber"}},
+	{With(Flash, MMS), &ConflictError{"flash", "mms"}},
+	{With(Flash, Mail), &ConflictError{"flash", "mail"}},
+	{With(Flash, Call), &ConflictError{"flash", "call"}},
+	{With(Bin, Viber), &ConflictError{"binary", "viber"}},
+	{With(Bin, MMS), &ConflictError{"binary", "mms"}},
+	{With(Bin, Mail), &ConflictError{"binary", "mail"}},
+	{With(Bin, Call), &ConflictError{"binary", "call"}},
+	{With(Viber, MMS), &ConflictError{"viber", "mms"}},
+	{With(Viber, Mail), &ConflictError{"viber", "mail"}},
+	{With(Viber, Call), &ConflictError{"viber", "call"}},
+	{With(MMS, Mail), &ConflictError{"mms", "mail"}},
+	{With(MMS, Call), &ConflictError{"mms", "call"}},
+	{With(Mail, Call), &ConflictError{"mail", "call"}},
+	{With(Translit, BinHex), &ConflictError{"translit", "binary"}},
+	{
+		With(WithSendTime(day), WithDeliveryWindow(day.Add(9*time.Hour), day.Add(21*time.Hour))),
+		&ConflictError{"send time", "delivery window"},
+	},
+	{
+		With(WithSendTime(day), WithLocalDeliveryWindow(9, 21)),
+		&ConflictError{"send time", "local delivery window"},
+	},
+	{
+		With(WithConcatRef(ref), WithLocalDeliveryWindow(9, 21)),
+		&ConflictError{"concat ref", "local delivery window"},
+	},
+	{With(Viber, WithFallbackChannels(ChannelSMS)), &ConflictError{"fallback channels", "viber"}},
+	{With(MMS, WithFallbackChannels(ChannelSMS)), &ConflictError{"fallback channels", "mms"}},
+	{With(Mail, WithFallbackChannels(ChannelSMS)), &ConflictError{"fallback channels", "mail"}},
+	{With(Call, WithFallbackChannels(ChannelSMS)), &ConflictError{"fallback channels", "call"}},
+	{With(Flash, WithFallbackChannels(ChannelSMS)), nil},
+}
+
+func TestMessage_Validate_conflicts(t *testing.T) {
+	for _, tt := range MessageValidateConflictsTests {
+		m := message{Text: "test", Phones: somePhone}
+		tt.Opt(&m)
+		t.Run(fmt.Sprintf("%v", tt.Err), func(t *testing.T) {
+			if err := m.Validate(); !reflect.DeepEqual(err, tt.Err) {
+				t.Errorf("want %v, got %v", tt.Err, err)
+			}
+		})
+	}
+}
+
 var MessageValuesTests = []struct {
 	Message message
 	Values  url.Values
 }{
+	{
+		message{
+			Phones:   somePhone,
+			SendTime: time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC),
+		},
+		url.Values{
+			"login":  []string{""},
+			"psw":    []string{""},
+			"mes":    []string{""},
+			"phones": somePhone,
+			"time":   []string{"02.01.20 15:04"},
+			"tz":     []string{"-3"},
+		},
+	},
 	{
 		message{
 			Login:    "me",
@@ -122,8 +235,19 @@ var MessageValuesTests = []struct {
 			Op:       Op,
 			Err:      Err,
 			Valid:    &valid{0, 1},
+			Window:   &window{9, 21, -3},
 			Sender:   "test",
 			Translit: Translit,
+			Flash:    Flash,
+			Bin:      BinHex,
+			Viber:    Viber,
+			MMS:      MMS,
+			Mail:     Mail,
+			Call:     Call,
+
+			CallbackURL:  "https://example.com",
+			Subject:      "subject",
+			ReceiptEmail: "me@example.com",
 		},
 		url.Values{
 			"login":    []string{""},
@@ -136,8 +260,20 @@ var MessageValuesTests = []struct {
 			"op":       []string{formatOpt(Op)},
 			"err":      []string{formatOpt(Err)},
 			"valid":    []string{formatOpt(&valid{0, 1})},
+			"time":     []string{"9-21"},
+			"tz":       []string{"-3"},
 			"sender":   []string{"test"},
 			"translit": []string{formatOpt(Translit)},
+			"flash":    []string{formatOpt(Flash)},
+			"bin":      []string{formatOpt(BinHex)},
+			"viber":    []string{formatOpt(Viber)},
+			"mms":      []string{formatOpt(MMS)},
+			"mail":     []string{formatOpt(Mail)},
+			"call":     []string{formatOpt(Call)},
+			"callback": []string{"https://example.com"},
+			"subj":     []string{"subject"},
+
+			"receipt_email": []string{"me@example.com"},
 		},
 	},
 }
@@ -149,3 +285,34 @@ func TestMessage_Values(t *testing.T) {
 		}
 	}
 }
+
+func TestMessageBuilder(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewMessage("test", somePhone).Sender("Shop").Flash()
+	msg := b.Build()
+	b.Sender("Other").Opts(WithTransactional())
+
+	m := c.prepare(msg.Text, msg.Phones, msg.options())
+	if m.Sender != "Shop" || m.Flash != Flash || m.Transactional {
+		t.Errorf("unexpected message %+v", m)
+	}
+	if !reflect.DeepEqual(m.Phones, somePhone) {
+		t.Errorf("phones: want %v, got %v", somePhone, m.Phones)
+	}
+}
+
+func TestMessage_Validate_tooManyRecipients(t *testing.T) {
+	m := message{Text: "test", Phones: make([]string, MaxRecipients)}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	m.Phones = append(m.Phones, "1")
+	want := &TooManyRecipientsError{Count: MaxRecipients + 1, Max: MaxRecipients}
+	if err := m.Validate(); !reflect.DeepEqual(err, want) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+}
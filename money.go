@@ -0,0 +1,99 @@
+package smsc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrBadMoney = errors.New("smsc: invalid money amount")
+
+// Money is an amount in minor units, e.g. kopecks. It avoids rounding errors
+// of floats in billing.
+type Money int64
+
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign, m = "-", -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON parses m from a JSON string or number.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	s := string(b)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := parseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// parseMoney parses an amount written by API. Both "." and "," separate
+// decimals. Digits after minor units must be zeros.
+func parseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+
+	units, frac := s, ""
+	if i := strings.IndexAny(s, ".,"); i >= 0 {
+		units, frac = s[:i], s[i+1:]
+	}
+	if units == "" || !isDigits(units) || !isDigits(frac) {
+		return 0, ErrBadMoney
+	}
+	if len(frac) > 2 {
+		if strings.Trim(frac[2:], "0") != "" {
+			return 0, ErrBadMoney
+		}
+		frac = frac[:2]
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+
+	n, err := strconv.ParseInt(units+frac, 10, 64)
+	if err != nil {
+		return 0, ErrBadMoney
+	}
+	if neg {
+		n = -n
+	}
+	return Money(n), nil
+}
+
+// parseOptionalMoney parses s if it is set.
+func parseOptionalMoney(s *string) (Money, error) {
+	if s == nil || *s == "" {
+		return 0, nil
+	}
+	return parseMoney(*s)
+}
+
+// isDigits tells whether s has only decimal digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package smsc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var ParseMoneyTests = []struct {
+	S     string
+	Money Money
+	Err   error
+}{
+	{"1.5", 150, nil},
+	{"1,5", 150, nil},
+	{"1.50", 150, nil},
+	{"1,500", 150, nil},
+	{"1.5000", 150, nil},
+	{"10", 1000, nil},
+	{"10.", 1000, nil},
+	{"0.01", 1, nil},
+	{" 2.35 ", 235, nil},
+	{"-3.2", -320, nil},
+	{"1.505", 0, ErrBadMoney},
+	{"", 0, ErrBadMoney},
+	{".5", 0, ErrBadMoney},
+	{"1.2.3", 0, ErrBadMoney},
+	{"1,2,3", 0, ErrBadMoney},
+	{"abc", 0, ErrBadMoney},
+}
+
+func TestParseMoney(t *testing.T) {
+	for _, tt := range ParseMoneyTests {
+		t.Run(tt.S, func(t *testing.T) {
+			m, err := parseMoney(tt.S)
+			if err != tt.Err {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+			if m != tt.Money {
+				t.Errorf("want %v, got %v", tt.Money, m)
+			}
+		})
+	}
+}
+
+var MoneyStringTests = []struct {
+	Money Money
+	S     string
+}{
+	{0, "0.00"},
+	{5, "0.05"},
+	{150, "1.50"},
+	{-320, "-3.20"},
+}
+
+func TestMoney_String(t *testing.T) {
+	for _, tt := range MoneyStringTests {
+		if s := tt.Money.String(); s != tt.S {
+			t.Errorf("want %q, got %q", tt.S, s)
+		}
+	}
+}
+
+func TestMoney_UnmarshalJSON(t *testing.T) {
+	var v struct {
+		A, B, C Money
+	}
+	if err := json.Unmarshal([]byte(`{"A": "1,5", "B": 2.25, "C": null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 150 || v.B != 225 || v.C != 0 {
+		t.Errorf("want 1.50, 2.25, 0.00, got %v, %v, %v", v.A, v.B, v.C)
+	}
+}
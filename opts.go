@@ -3,9 +3,16 @@ package smsc
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"time"
 )
 
-var ErrBadValid = errors.New("smsc: invalid period for valid")
+var (
+	ErrBadValid  = errors.New("smsc: invalid period for valid")
+	ErrExpired   = errors.New("smsc: expiry time is in the past")
+	ErrBadWindow = errors.New("smsc: invalid delivery window")
+	ErrBadURL    = errors.New("smsc: callback url must be https")
+)
 
 // Opt configures a send message and a Result.
 type Opt func(*message)
@@ -24,6 +31,18 @@ func With(v ...interface{}) Opt {
 			opts = append(opts, func(m *message) { m.Err = o })
 		case TranslitOpt:
 			opts = append(opts, func(m *message) { m.Translit = o })
+		case FlashOpt:
+			opts = append(opts, func(m *message) { m.Flash = o })
+		case BinOpt:
+			opts = append(opts, func(m *message) { m.Bin = o })
+		case ViberOpt:
+			opts = append(opts, func(m *message) { m.Viber = o })
+		case MMSOpt:
+			opts = append(opts, func(m *message) { m.MMS = o })
+		case MailOpt:
+			opts = append(opts, func(m *message) { m.Mail = o })
+		case CallOpt:
+			opts = append(opts, func(m *message) { m.Call = o })
 		case Opt:
 			opts = append(opts, o)
 		default:
@@ -58,7 +77,8 @@ const (
 	CostCountBalance
 )
 
-// charset defines message text encoding. utf-8 is used always.
+// charset defines message text encoding. utf-8 is used always. Responses
+// are decoded by charset of their Content-Type.
 type charset string
 
 const (
@@ -80,13 +100,42 @@ type ErrOpt int
 const Err ErrOpt = 1
 
 func Valid(h, m int) Opt {
+	return newValid(h, m).Apply
+}
+
+// newValid returns a valid of h hours and m minutes. It panics with
+// ErrBadValid when the period is not within 24 hours.
+func newValid(h, m int) *valid {
 	if (h < 0 || m < 0) ||
 		(h > 24 || m > 59) ||
 		(h == 0 && m < 1) ||
 		(h == 24 && m > 0) {
 		panic(ErrBadValid)
 	}
-	return (&valid{h, m}).Apply
+	return &valid{h, m}
+}
+
+// WithExpiryTime makes an operator stop trying to send a message at t.
+//
+// t is converted to Valid relative to a send time, so it must be within 24
+// hours after it.
+func WithExpiryTime(t time.Time) Opt {
+	return func(m *message) { m.ExpiresAt = t }
+}
+
+// validUntil returns a valid which ends at t for a message sent at now.
+// Minutes are rounded up.
+func validUntil(now, t time.Time) (*valid, error) {
+	d := t.Sub(now)
+	if d <= 0 {
+		return nil, ErrExpired
+	}
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	v := &valid{minutes / 60, minutes % 60}
+	if v.Hours > 24 || (v.Hours == 24 && v.Minutes > 0) {
+		return nil, ErrBadValid
+	}
+	return v, nil
 }
 
 // valid defines how long an operator must try to send a message.
@@ -100,14 +149,146 @@ func (v *valid) Apply(m *message) {
 }
 
 func (v *valid) String() string {
-	var h, m string
-	if v.Hours < 9 {
-		h = "0"
+	return fmt.Sprintf("%02d:%02d", v.Hours, v.Minutes)
+}
+
+// WithDeliveryWindow restricts delivery to hours between start and end.
+//
+// Only hours are used, so the window is [start.Hour(), end.Hour()) in the
+// start location. start and end must be of the same day and start must be
+// before end. end within the first hour of the next day means 24, e.g.
+// midnight.
+func WithDeliveryWindow(start, end time.Time) Opt {
+	end = end.In(start.Location())
+	to := end.Hour()
+	if next := start.AddDate(0, 0, 1); to == 0 && sameDay(end, next) {
+		end, to = start, 24
 	}
-	if v.Minutes < 9 {
-		m = "0"
+	if !sameDay(start, end) || start.Hour() >= to {
+		panic(ErrBadWindow)
+	}
+	return (&window{start.Hour(), to, tzOf(start)}).Apply
+}
+
+// sameDay tells whether a and b are of the same date.
+func sameDay(a, b time.Time) bool {
+	y1, m1, d1 := a.Date()
+	y2, m2, d2 := b.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+// WithLocalDeliveryWindow restricts delivery to hours [from, to) in a local
+// time of each recipient, e.g. 9 and 21.
+//
+// A local time is found by a phone country, phones of unknown countries use
+// Moscow time. Russian phones use Moscow time too, since a region cannot be
+// told by a phone. Phones of different timezones are sent by separate
+// requests, Result.Parts holds their Results then.
+func WithLocalDeliveryWindow(from, to int) Opt {
+	if from < 0 || to > 24 || from >= to {
+		panic(ErrBadWindow)
 	}
-	return fmt.Sprintf("%s%d:%s%d", h, v.Hours, m, v.Minutes)
+	return func(m *message) { m.LocalWindow = &window{From: from, To: to} }
+}
+
+// mskOffset is an offset of Moscow time from UTC in hours. API expects
+// timezones relative to Moscow.
+const mskOffset = 3
+
+// window defines hours when an operator may deliver a message.
+type window struct {
+	From int
+	To   int
+	TZ   int // hours relative to Moscow
+}
+
+func (w *window) Apply(m *message) {
+	m.Window = w
+}
+
+func (w *window) String() string {
+	return fmt.Sprintf("%d-%d", w.From, w.To)
+}
+
+// WithSendTime schedules a message to be sent at t.
+func WithSendTime(t time.Time) Opt {
+	return func(m *message) { m.SendTime = t }
+}
+
+// sendTimeLayout is a format of a time parameter.
+const sendTimeLayout = "02.01.06 15:04"
+
+// tzOf returns a timezone of t relative to Moscow in hours.
+func tzOf(t time.Time) int {
+	_, offset := t.Zone()
+	return offset/3600 - mskOffset
+}
+
+// WithReceiptEmail sends a copy of delivery receipts to addr. An invalid
+// address is rejected by Validate.
+func WithReceiptEmail(addr string) Opt {
+	return func(m *message) { m.ReceiptEmail = addr }
+}
+
+// WithCallbackURL sets a URL which receives delivery reports of a message.
+// Use ParseCallback to read a report.
+//
+// If the account doesn't support per-message callbacks, a send fails with an
+// error which matches ErrCallbackNotSupported and wraps an *Error of API.
+func WithCallbackURL(s string) Opt {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		panic(ErrBadURL)
+	}
+	return func(m *message) { m.CallbackURL = s }
+}
+
+// WithTerseResponse requests a plain text response instead of JSON.
+//
+// Only Result.ID and Result.Count are returned. It is cheaper to parse, so use
+// it when other fields are not needed.
+func WithTerseResponse() Opt {
+	return func(m *message) { m.Format = formatInlineVerbose }
+}
+
+// WithVerboseErrors requests a reason of failure for each failed phone. API
+// reports it in Phone.Error and Phone.ErrorCode.
+func WithVerboseErrors() Opt {
+	return func(m *message) { m.Err = Err }
+}
+
+// WithForceSend sends a message without client-side validation, e.g. when API
+// changed a rule and Validate rejects a valid message. It skips
+// Config.ValidateSenderLocally too. A warning is logged to Config.Logger. It
+// is an escape hatch for incidents, API still rejects invalid messages.
+func WithForceSend() Opt {
+	return func(m *message) { m.Force = true }
+}
+
+// WithPlainPassword sends Config.Password as is instead of its MD5 hash for a
+// single send. It is for endpoints which do not accept a hash.
+//
+// A plain password is exposed to anyone who can read the request, e.g. proxy
+// logs, so use it only for sends which need it. ErrNoPlainPassword is
+// returned when a Client is configured with PasswordMD5 only.
+func WithPlainPassword() Opt {
+	return func(m *message) { m.PlainPassword = true }
+}
+
+// WithTimeout limits a send duration by d.
+//
+// It is implemented with a context deadline, so a shared http.Client and its
+// transport are not changed.
+func WithTimeout(d time.Duration) Opt {
+	return func(m *message) { m.Timeout = d }
+}
+
+// WithConcatRef sends a long binary message by parts with ref as a
+// concatenation reference in UDH, so a phone reassembles parts correctly.
+//
+// It requires Bin or BinHex and a payload longer than a single SMS.
+func WithConcatRef(ref uint8) Opt {
+	return func(m *message) { m.ConcatRef = &ref }
 }
 
 // Sender sets the author of SMS.
@@ -122,10 +303,42 @@ const (
 	TranslitKlinopis
 )
 
+// FlashOpt sends a message as flash SMS which is shown on a screen at once.
+type FlashOpt int
+
+const Flash FlashOpt = 1
+
+// BinOpt defines that a message text is binary data.
+type BinOpt int
+
+const (
+	Bin BinOpt = iota + 1
+	BinHex
+)
+
+// ViberOpt sends a message to Viber.
+type ViberOpt int
+
+const Viber ViberOpt = 1
+
+// MMSOpt sends a message as MMS.
+type MMSOpt int
+
+const MMS MMSOpt = 1
+
+// MailOpt sends a message by e-mail.
+type MailOpt int
+
+const Mail MailOpt = 1
+
+// CallOpt sends a message as a voice call.
+type CallOpt int
+
+const Call CallOpt = 1
+
 // TODO: Add more options.
-// ID, Subj, TinyURL, Time, Tz, Period, Freq, Flash, Bin,
-// Push, HLR, Ping, MMS, Mail, Viber, FileURL, Call, Voice, List, MaxSMS,
-// ImgCode, UserIP, PP.
+// TinyURL, Period, Freq, Push, HLR, Ping, FileURL,
+// Voice, List, MaxSMS, ImgCode, UserIP.
 
 // formatOpt retuns a string for v value of option.
 func formatOpt(v interface{}) string {
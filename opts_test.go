@@ -2,7 +2,9 @@ package smsc
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 )
 
 var ValidPanicTests = []struct {
@@ -47,3 +49,136 @@ func TestValid_String(t *testing.T) {
 		}
 	}
 }
+
+var day = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var WithDeliveryWindowPanicTests = []struct {
+	Start, End time.Time
+}{
+	{day.Add(21 * time.Hour), day.Add(9 * time.Hour)},
+	{day.Add(9 * time.Hour), day.Add(9*time.Hour + 30*time.Minute)},
+	{day.Add(21 * time.Hour), day.Add(33 * time.Hour)},
+	{day.Add(21 * time.Hour), day.Add(49 * time.Hour)},
+}
+
+func TestWithDeliveryWindow_panics(t *testing.T) {
+	for _, tt := range WithDeliveryWindowPanicTests {
+		t.Run(fmt.Sprintf("%v-%v", tt.Start, tt.End), func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatal()
+				}
+			}()
+			WithDeliveryWindow(tt.Start, tt.End)
+		})
+	}
+}
+
+func TestWithDeliveryWindow(t *testing.T) {
+	var m message
+	WithDeliveryWindow(day.Add(9*time.Hour), day.Add(21*time.Hour))(&m)
+	if want := (&window{9, 21, -3}); !reflect.DeepEqual(m.Window, want) {
+		t.Errorf("want %v, got %v", want, m.Window)
+	}
+}
+
+func TestWithDeliveryWindow_midnight(t *testing.T) {
+	for _, end := range []time.Time{day.Add(24 * time.Hour), day.Add(24*time.Hour + 30*time.Minute)} {
+		var m message
+		WithDeliveryWindow(day.Add(9*time.Hour), end)(&m)
+		if want := (&window{9, 24, -3}); !reflect.DeepEqual(m.Window, want) {
+			t.Errorf("%v: want %v, got %v", end, want, m.Window)
+		}
+	}
+}
+
+var WithCallbackURLPanicTests = []string{
+	"",
+	"http://example.com",
+	"https://",
+	"://example.com",
+}
+
+func TestWithCallbackURL_panics(t *testing.T) {
+	for _, s := range WithCallbackURLPanicTests {
+		t.Run(s, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatal()
+				}
+			}()
+			WithCallbackURL(s)
+		})
+	}
+}
+
+var ValidUntilTests = []struct {
+	Expiry time.Time
+	Valid  *valid
+	String string
+	Err    error
+}{
+	{day.Add(30 * time.Second), &valid{0, 1}, "00:01", nil},
+	{day.Add(9 * time.Minute), &valid{0, 9}, "00:09", nil},
+	{day.Add(90 * time.Minute), &valid{1, 30}, "01:30", nil},
+	{day.Add(9 * time.Hour), &valid{9, 0}, "09:00", nil},
+	{day.Add(24 * time.Hour), &valid{24, 0}, "24:00", nil},
+	{day.Add(24*time.Hour + time.Second), nil, "", ErrBadValid},
+	{day, nil, "", ErrExpired},
+	{day.Add(-time.Hour), nil, "", ErrExpired},
+}
+
+func TestValidUntil(t *testing.T) {
+	for _, tt := range ValidUntilTests {
+		t.Run(tt.Expiry.String(), func(t *testing.T) {
+			v, err := validUntil(day, tt.Expiry)
+			if err != tt.Err {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+			if !reflect.DeepEqual(v, tt.Valid) {
+				t.Errorf("valid: want %v, got %v", tt.Valid, v)
+			}
+			if v != nil && v.String() != tt.String {
+				t.Errorf("string: want %q, got %q", tt.String, v.String())
+			}
+		})
+	}
+}
+
+func TestClient_check_expiryTime(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return day }
+
+	m := c.prepare("test", somePhone, []Opt{WithExpiryTime(day.Add(2 * time.Hour))})
+	if err := c.check(m); err != nil {
+		t.Fatal(err)
+	}
+	if want := (&valid{2, 0}); !reflect.DeepEqual(m.Valid, want) {
+		t.Errorf("want %v, got %v", want, m.Valid)
+	}
+
+	// Expiry is relative to a scheduled time.
+	m = c.prepare("test", somePhone, []Opt{WithSendTime(day.Add(time.Hour)), WithExpiryTime(day.Add(2 * time.Hour))})
+	if err := c.check(m); err != nil {
+		t.Fatal(err)
+	}
+	if want := (&valid{1, 0}); !reflect.DeepEqual(m.Valid, want) {
+		t.Errorf("want %v, got %v", want, m.Valid)
+	}
+
+	m = c.prepare("test", somePhone, []Opt{Valid(1, 0), WithExpiryTime(day.Add(2 * time.Hour))})
+	if err := c.check(m); !reflect.DeepEqual(err, &ConflictError{"valid", "expiry time"}) {
+		t.Errorf("want conflict, got %v", err)
+	}
+}
+
+func TestWithVerboseErrors(t *testing.T) {
+	m := &message{}
+	WithVerboseErrors()(m)
+	if v := m.Values().Get("err"); v != "1" {
+		t.Errorf("err: want 1, got %q", v)
+	}
+}
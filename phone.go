@@ -0,0 +1,215 @@
+package smsc
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrBadCountry     = errors.New("smsc: unknown default country")
+	ErrBadPrefixTable = errors.New("smsc: invalid prefix table")
+)
+
+// PhoneError is returned when a phone cannot be converted to international
+// format.
+type PhoneError struct {
+	Phone string
+}
+
+func (e *PhoneError) Error() string {
+	return fmt.Sprintf("smsc: ambiguous phone %q", e.Phone)
+}
+
+// Country defines how phones are written in a country.
+type Country struct {
+	Code   string // calling code
+	Trunk  string // prefix of local phones
+	Length int    // length of a national phone
+
+	// Prefixes of national phones which distinguish countries with a shared
+	// calling code. Empty means any phone with Code.
+	Prefixes []string
+
+	// Offset is a standard UTC offset of the capital in hours. Daylight
+	// saving time is not taken into account.
+	Offset int
+}
+
+// PrefixTable maps ISO 3166-1 alpha-2 codes to countries.
+type PrefixTable map[string]Country
+
+//go:embed prefixes.csv
+var prefixesCSV string
+
+// countries is the default PrefixTable of CIS countries.
+var countries = mustParsePrefixes(prefixesCSV)
+
+// mustParsePrefixes parses a PrefixTable from CSV s. Lines are
+// "iso,code,trunk,length,prefixes,offset", prefixes are separated by spaces.
+func mustParsePrefixes(s string) PrefixTable {
+	r := csv.NewReader(strings.NewReader(s))
+	r.Comment = '#'
+	r.FieldsPerRecord = 6
+	records, err := r.ReadAll()
+	if err != nil {
+		panic(err)
+	}
+	t := make(PrefixTable, len(records))
+	for _, rec := range records {
+		length, err := strconv.Atoi(rec[3])
+		if err != nil {
+			panic(err)
+		}
+		offset, err := strconv.Atoi(rec[5])
+		if err != nil {
+			panic(err)
+		}
+		t[rec[0]] = Country{rec[1], rec[2], length, strings.Fields(rec[4]), offset}
+	}
+	return t
+}
+
+// merge returns a copy of t with countries of u added or replaced.
+func (t PrefixTable) merge(u PrefixTable) (PrefixTable, error) {
+	out := make(PrefixTable, len(t)+len(u))
+	for iso, c := range t {
+		out[iso] = c
+	}
+	for iso, c := range u {
+		if c.Code == "" || !isDigits(c.Code) || c.Length <= 0 {
+			return nil, ErrBadPrefixTable
+		}
+		out[strings.ToUpper(iso)] = c
+	}
+	return out, nil
+}
+
+// LookupCountry returns an ISO code of an international phone country by the
+// default prefix table.
+func LookupCountry(phone string) (code string, ok bool) {
+	return countries.lookup(phone)
+}
+
+// LookupCountry returns an ISO code of an international phone country by
+// Config.PrefixTable merged with the default one.
+func (c *Client) LookupCountry(phone string) (code string, ok bool) {
+	return c.prefixes.lookup(phone)
+}
+
+// lookup returns an ISO code of an international phone country. The longest
+// matching prefix wins.
+func (t PrefixTable) lookup(phone string) (string, bool) {
+	digits := strings.TrimPrefix(phone, "+")
+
+	var code string
+	var size int
+	for iso, c := range t {
+		if !strings.HasPrefix(digits, c.Code) {
+			continue
+		}
+		n := len(c.Code)
+		if len(c.Prefixes) > 0 {
+			rest := digits[len(c.Code):]
+			matched := false
+			for _, p := range c.Prefixes {
+				if strings.HasPrefix(rest, p) {
+					matched = true
+					n += len(p)
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		if n > size {
+			code, size = iso, n
+		}
+	}
+	return code, size > 0
+}
+
+// normalizePhone returns phone in E.164 format. Phones without a leading "+"
+// are treated as local phones of c.
+func normalizePhone(phone string, c Country) (string, error) {
+	var b strings.Builder
+	b.Grow(len(phone) + 1)
+
+	plus := false
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			plus = true
+		case r == ' ', r == '-', r == '(', r == ')', r == '.':
+		default:
+			return "", &PhoneError{phone}
+		}
+	}
+
+	digits := b.String()
+	switch {
+	case digits == "":
+	case plus:
+		return "+" + digits, nil
+	case c.Trunk != "" && len(digits) == len(c.Trunk)+c.Length && strings.HasPrefix(digits, c.Trunk):
+		return "+" + c.Code + digits[len(c.Trunk):], nil
+	case len(digits) == c.Length:
+		return "+" + c.Code + digits, nil
+	case len(digits) == len(c.Code)+c.Length && strings.HasPrefix(digits, c.Code):
+		return "+" + digits, nil
+	}
+	return "", &PhoneError{phone}
+}
+
+// normalizePhones converts m.Phones to E.164 format.
+func (m *message) normalizePhones(c Country) error {
+	phones := make([]string, len(m.Phones))
+	for i, p := range m.Phones {
+		s, err := normalizePhone(p, c)
+		if err != nil {
+			return err
+		}
+		phones[i] = s
+	}
+	m.Phones = phones
+	return nil
+}
+
+// WithDedup removes duplicate phones before sending, so a phone is not billed
+// twice. Phones are compared after normalization and the order is kept.
+// Result.Meta.Duplicates reports a number of dropped phones.
+func WithDedup() Opt {
+	return func(m *message) { m.Dedup = true }
+}
+
+// dedupPhones returns phones without duplicates and a number of dropped ones.
+func dedupPhones(phones []string) ([]string, int) {
+	seen := make(map[string]bool, len(phones))
+	out := make([]string, 0, len(phones))
+	for _, p := range phones {
+		key := phoneKey(p)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, p)
+	}
+	return out, len(phones) - len(out)
+}
+
+// phoneKey returns digits of phone, so differently formatted phones match.
+func phoneKey(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package smsc
+
+import (
+	"reflect"
+	"testing"
+)
+
+var NormalizePhoneTests = []struct {
+	Country string
+	Phone   string
+	S       string
+	Err     error
+}{
+	{"RU", "+7 (916) 123-45-67", "+79161234567", nil},
+	{"RU", "8 916 123 45 67", "+79161234567", nil},
+	{"RU", "79161234567", "+79161234567", nil},
+	{"RU", "9161234567", "+79161234567", nil},
+	{"RU", "+375291234567", "+375291234567", nil},
+	{"RU", "916123456", "", &PhoneError{"916123456"}},
+	{"RU", "69161234567", "", &PhoneError{"69161234567"}},
+	{"RU", "8-916-CALL-ME", "", &PhoneError{"8-916-CALL-ME"}},
+	{"RU", "", "", &PhoneError{""}},
+	{"BY", "80291234567", "+375291234567", nil},
+	{"BY", "291234567", "+375291234567", nil},
+	{"UA", "0501234567", "+380501234567", nil},
+	{"UZ", "901234567", "+998901234567", nil},
+}
+
+func TestNormalizePhone(t *testing.T) {
+	for _, tt := range NormalizePhoneTests {
+		t.Run(tt.Country+" "+tt.Phone, func(t *testing.T) {
+			s, err := normalizePhone(tt.Phone, countries[tt.Country])
+			if !reflect.DeepEqual(err, tt.Err) {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+			if s != tt.S {
+				t.Errorf("want %q, got %q", tt.S, s)
+			}
+		})
+	}
+}
+
+var LookupCountryTests = []struct {
+	Phone   string
+	Country string
+	OK      bool
+}{
+	{"+79161234567", "RU", true},
+	{"79161234567", "RU", true},
+	{"+77011234567", "KZ", true},
+	{"+375291234567", "BY", true},
+	{"+998901234567", "UZ", true},
+	{"+11234567890", "", false},
+}
+
+func TestLookupCountry(t *testing.T) {
+	for _, tt := range LookupCountryTests {
+		t.Run(tt.Phone, func(t *testing.T) {
+			s, ok := LookupCountry(tt.Phone)
+			if s != tt.Country || ok != tt.OK {
+				t.Errorf("want %q/%v, got %q/%v", tt.Country, tt.OK, s, ok)
+			}
+		})
+	}
+}
+
+func TestDedupPhones(t *testing.T) {
+	phones := []string{"+79161234567", "+71234567890", "+7 916 123-45-67", "+79161234567", "+375291234567"}
+	want := []string{"+79161234567", "+71234567890", "+375291234567"}
+
+	out, n := dedupPhones(phones)
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("want %v, got %v", want, out)
+	}
+	if n != 2 {
+		t.Errorf("dropped: want 2, got %d", n)
+	}
+}
+
+func TestConfig_PrefixTable(t *testing.T) {
+	_, err := New(Config{Login: "test", Password: "pass", PrefixTable: PrefixTable{"XX": {Code: "x1"}}})
+	if err != ErrBadPrefixTable {
+		t.Errorf("want %v, got %v", ErrBadPrefixTable, err)
+	}
+
+	c, err := New(Config{
+		Login:          "test",
+		Password:       "pass",
+		DefaultCountry: "rs",
+		PrefixTable:    PrefixTable{"rs": {Code: "381", Trunk: "0", Length: 9, Offset: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, ok := c.LookupCountry("+381601234567"); code != "RS" || !ok {
+		t.Errorf("want RS, got %q/%v", code, ok)
+	}
+	if code, ok := c.LookupCountry("+79161234567"); code != "RU" || !ok {
+		t.Errorf("default: want RU, got %q/%v", code, ok)
+	}
+	if _, ok := LookupCountry("+381601234567"); ok {
+		t.Error("default table is changed")
+	}
+}
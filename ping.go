@@ -0,0 +1,50 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// PingResult describes API availability.
+type PingResult struct {
+	Latency time.Duration // of a round trip
+
+	// ServerTime is a time reported by API. It is zero when API did not send
+	// it. Its precision is a second.
+	ServerTime time.Time
+
+	// Skew is ServerTime minus a local time of the response. It is zero when
+	// ServerTime is zero.
+	Skew time.Duration
+
+	// Server is software of API as it reports, when available.
+	Server string
+}
+
+// PingInfo checks that API is available and credentials are valid. API has
+// no test script, so a balance request is made.
+func (c *Client) PingInfo(ctx context.Context) (*PingResult, error) {
+	start := c.now()
+	var b json.RawMessage
+	h, err := c.callHeader(ctx, "balance.php", url.Values{}, &b)
+	if err != nil {
+		return nil, err
+	}
+	end := c.now()
+
+	p := &PingResult{Latency: end.Sub(start), Server: h.Get("Server")}
+	if t, err := http.ParseTime(h.Get("Date")); err == nil {
+		p.ServerTime = t
+		p.Skew = t.Sub(end)
+	}
+	return p, nil
+}
+
+// Ping checks that API is available and credentials are valid.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.PingInfo(ctx)
+	return err
+}
@@ -0,0 +1,54 @@
+package smsc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_PingInfo(t *testing.T) {
+	serverTime := day.Add(time.Minute)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/balance.php" {
+			t.Errorf("path: want %q, got %q", "/balance.php", r.URL.Path)
+		}
+		w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+		w.Header().Set("Server", "nginx")
+		w.Write([]byte(`{"balance": "100.50"}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", StrictParsing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return day }
+
+	p, err := c.PingInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PingResult{ServerTime: serverTime, Skew: time.Minute, Server: "nginx"}
+	if !reflect.DeepEqual(want, p) {
+		t.Errorf("want %+v, got %+v", want, p)
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error": "authorise error", "error_code": 2}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Error{Code: CodeAuth, Desc: "authorise error"}
+	if err := c.Ping(context.Background()); !reflect.DeepEqual(want, err) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+}
@@ -0,0 +1,98 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// PhonePreview is an estimated cost of a message to a phone.
+type PhonePreview struct {
+	Phone    string
+	Operator string // empty when unknown
+	Parts    int
+
+	// Cost is Parts times a price of Operator. It is zero and Known is false
+	// when an operator or its price is unknown.
+	Cost  Money
+	Known bool
+}
+
+// Preview estimates parts and cost of text to each of phones without sending
+// it. Parts are counted by EstimateParts, with transliteration of Translit
+// option applied, and prices are taken from Tariffs of API.
+func (c *Client) Preview(ctx context.Context, text string, phones []string, opts ...Opt) ([]PhonePreview, error) {
+	m := c.prepare(text, phones, opts)
+	if c.country != nil {
+		if err := m.normalizePhones(*c.country); err != nil {
+			return nil, err
+		}
+	}
+	parts := EstimateParts(m.Text)
+	if m.Translit != 0 {
+		parts = EstimateParts(translitText(m.Text))
+	}
+	tariffs, err := c.cachedTariffs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]PhonePreview, len(m.Phones))
+	for i, phone := range m.Phones {
+		p := PhonePreview{Phone: phone, Parts: parts}
+		info, err := c.phoneInfo(ctx, phone)
+		var e *Error
+		switch {
+		case errors.As(err, &e) && e.Code == CodePhone:
+		case err != nil:
+			return nil, err
+		default:
+			p.Operator = info.Operator
+		}
+		if price, ok := priceOf(tariffs, p.Operator); ok {
+			p.Cost = price * Money(parts)
+			p.Known = true
+		}
+		out[i] = p
+	}
+	return out, nil
+}
+
+// priceOf returns a price of operator by a default route.
+func priceOf(tariffs []Tariff, operator string) (Money, bool) {
+	if operator == "" {
+		return 0, false
+	}
+	for _, t := range tariffs {
+		if t.Sender == "" && strings.EqualFold(t.Operator, operator) {
+			return t.Price, true
+		}
+	}
+	return 0, false
+}
+
+// translit maps cyrillic letters which take more than one latin letter.
+var translit = map[rune]string{
+	'ж': "zh", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sch",
+	'ё': "yo", 'ю': "yu", 'я': "ya",
+	'Ж': "Zh", 'Х': "Kh", 'Ц': "Ts", 'Ч': "Ch", 'Ш': "Sh", 'Щ': "Sch",
+	'Ё': "Yo", 'Ю': "Yu", 'Я': "Ya",
+}
+
+// translitText returns text as long as API transliterates it. Letters which
+// are not in translit are replaced with a single latin letter.
+func translitText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch s, ok := translit[r]; {
+		case ok:
+			b.WriteString(s)
+		case r >= utf8.RuneSelf:
+			b.WriteByte('x')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Preview(t *testing.T) {
+	operators := map[string]string{
+		"+79161234567": "MTS",
+		"+79031234567": "Tele2",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tariffs.php":
+			json.NewEncoder(w).Encode([]Tariff{{Operator: "MTS", Price: 150}})
+		case "/info.php":
+			op, ok := operators[r.PostFormValue("phone")]
+			if !ok {
+				json.NewEncoder(w).Encode(&Error{Code: CodePhone, Desc: "invalid number"})
+				return
+			}
+			json.NewEncoder(w).Encode(&PhoneInfo{Operator: op})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	phones := []string{"+79161234567", "+79031234567", "+70000000000"}
+	text := Generate(abcRus, 71)
+
+	pp, err := c.Preview(context.Background(), text, phones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PhonePreview{
+		{Phone: "+79161234567", Operator: "MTS", Parts: 2, Cost: 300, Known: true},
+		{Phone: "+79031234567", Operator: "Tele2", Parts: 2},
+		{Phone: "+70000000000", Parts: 2},
+	}
+	if !reflect.DeepEqual(want, pp) {
+		t.Errorf("want %+v, got %+v", want, pp)
+	}
+
+	pp, err = c.Preview(context.Background(), text, phones[:1], With(Translit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pp[0].Parts != 1 || pp[0].Cost != 150 {
+		t.Errorf("translit: want 1 part of 1.50, got %+v", pp[0])
+	}
+}
+
+func TestTranslitText(t *testing.T) {
+	if s := translitText("Щука, ёж!"); s != "Schxxx, yozh!" {
+		t.Errorf("got %q", s)
+	}
+}
@@ -0,0 +1,63 @@
+package smsc
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrQuietHours    = errors.New("smsc: quiet hours")
+	ErrBadQuietHours = errors.New("smsc: invalid quiet hours")
+)
+
+// QuietHours is a daily period when non-transactional messages are not sent.
+//
+// Start and End are offsets from midnight in Location. If End is before Start,
+// the period crosses midnight, e.g. 21:00-09:00. Start must be within a day,
+// End may be 24h, and they must differ.
+type QuietHours struct {
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// validate returns ErrBadQuietHours unless q is a valid period.
+func (q *QuietHours) validate() error {
+	const day = 24 * time.Hour
+	if q.Start < 0 || q.Start >= day || q.End < 0 || q.End > day || q.Start == q.End {
+		return ErrBadQuietHours
+	}
+	return nil
+}
+
+// Contains tells whether t is within the quiet hours.
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q.Location != nil {
+		t = t.In(q.Location)
+	}
+	h, m, s := t.Clock()
+	d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+
+	if q.Start <= q.End {
+		return q.Start <= d && d < q.End
+	}
+	return d >= q.Start || d < q.End
+}
+
+// WithTransactional marks a message as transactional, e.g. one-time password.
+// Transactional messages are sent during Config.QuietHours.
+func WithTransactional() Opt {
+	return func(m *message) { m.Transactional = true }
+}
+
+// checkQuietHours returns ErrQuietHours if m must not be sent now. Cost
+// requests send nothing, so they are not checked.
+func (c *Client) checkQuietHours(m *message) error {
+	if c.quiet == nil || m.Transactional || m.Cost == CostWithoutSend {
+		return nil
+	}
+	if c.quiet.Contains(c.now()) {
+		return ErrQuietHours
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package smsc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var msk = time.FixedZone("MSK", 3*60*60)
+
+var QuietHoursContainsTests = []struct {
+	Name     string
+	Quiet    QuietHours
+	Time     time.Time
+	Contains bool
+}{
+	{
+		"Day period",
+		QuietHours{Start: 13 * time.Hour, End: 14 * time.Hour},
+		time.Date(2020, 1, 1, 13, 30, 0, 0, time.UTC),
+		true,
+	},
+	{
+		"End is excluded",
+		QuietHours{Start: 13 * time.Hour, End: 14 * time.Hour},
+		time.Date(2020, 1, 1, 14, 0, 0, 0, time.UTC),
+		false,
+	},
+	{
+		"Before midnight",
+		QuietHours{Start: 21 * time.Hour, End: 9 * time.Hour},
+		time.Date(2020, 1, 1, 23, 59, 59, 0, time.UTC),
+		true,
+	},
+	{
+		"Midnight",
+		QuietHours{Start: 21 * time.Hour, End: 9 * time.Hour},
+		time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		true,
+	},
+	{
+		"After midnight",
+		QuietHours{Start: 21 * time.Hour, End: 9 * time.Hour},
+		time.Date(2020, 1, 2, 8, 59, 0, 0, time.UTC),
+		true,
+	},
+	{
+		"Day after midnight period",
+		QuietHours{Start: 21 * time.Hour, End: 9 * time.Hour},
+		time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC),
+		false,
+	},
+	{
+		"Location is applied",
+		QuietHours{Start: 21 * time.Hour, End: 9 * time.Hour, Location: msk},
+		time.Date(2020, 1, 1, 19, 0, 0, 0, time.UTC), // 22:00 MSK
+		true,
+	},
+}
+
+func TestQuietHours_Contains(t *testing.T) {
+	for _, tt := range QuietHoursContainsTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if v := tt.Quiet.Contains(tt.Time); v != tt.Contains {
+				t.Errorf("want %v, got %v", tt.Contains, v)
+			}
+		})
+	}
+}
+
+func TestClient_Send_quietHours(t *testing.T) {
+	c, err := New(Config{
+		URL:        "http://localhost:0",
+		Login:      "test",
+		Password:   "pass",
+		QuietHours: &QuietHours{Start: 21 * time.Hour, End: 9 * time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return time.Date(2020, 1, 1, 23, 0, 0, 0, time.UTC) }
+
+	if _, err := c.Send("test", somePhone); err != ErrQuietHours {
+		t.Errorf("want %v, got %v", ErrQuietHours, err)
+	}
+	// Transactional message passes the check and fails on request.
+	if _, err := c.Send("test", somePhone, WithTransactional()); err == ErrQuietHours {
+		t.Errorf("want request error, got %v", err)
+	}
+	if _, err := c.Cost(context.Background(), "test", somePhone); err == ErrQuietHours {
+		t.Errorf("cost: want request error, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package smsc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter spaces requests evenly by a rate of Config.RateLimit. A nil limiter
+// does not limit.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // of a next free slot
+	now      func() time.Time
+}
+
+// newLimiter returns a limiter of rate requests per second, or nil if rate is
+// not positive.
+func newLimiter(rate float64) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / rate), now: time.Now}
+}
+
+// wait blocks until a request may be made or ctx is done. A slot of a
+// cancelled wait is not returned.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := l.now()
+	t := l.next
+	if t.Before(now) {
+		t = now
+	}
+	l.next = t.Add(l.interval)
+	l.mu.Unlock()
+
+	if d := t.Sub(now); d > 0 {
+		return sleep(ctx, d)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimiter_wait(t *testing.T) {
+	now := day
+	l := newLimiter(10)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		want := day.Add(time.Duration(i+1) * 100 * time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
+		if i > 0 {
+			cancel() // a wait is not needed for the first request only
+		}
+		err := l.wait(ctx)
+		cancel()
+		if i == 0 && err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && err != context.Canceled {
+			t.Errorf("%d: want %v, got %v", i, context.Canceled, err)
+		}
+		if !l.next.Equal(want) {
+			t.Errorf("%d: want next slot at %v, got %v", i, want, l.next)
+		}
+	}
+
+	// Slots of the past are not accumulated.
+	now = day.Add(time.Hour)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(100 * time.Millisecond); !l.next.Equal(want) {
+		t.Errorf("want next slot at %v, got %v", want, l.next)
+	}
+}
+
+func TestLimiter_nil(t *testing.T) {
+	if l := newLimiter(0); l != nil {
+		t.Errorf("want nil, got %v", l)
+	}
+	var l *limiter
+	if err := l.wait(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConfig_RateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", RateLimit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := c.WithCredentials("other", "pass")
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := c.Send("test", somePhone); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cc.Send("test", somePhone); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 6 requests take 5 intervals of 10ms at least.
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("want 50ms at least, got %v", d)
+	}
+}
+
+func TestConfig_RateLimit_closesBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", RateLimit: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if _, err := c.SendFromFile(ctx, "test", strings.NewReader(somePhone[0])); err == nil {
+			t.Fatal("want an error of a limiter")
+		}
+		cancel()
+	}
+	// Writers of bodies exit once the pipe is closed.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("want %d goroutines at most, got %d", before, n)
+	}
+}
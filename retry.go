@@ -0,0 +1,141 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default pauses between retries, see Config.MaxRetries.
+const (
+	DefaultRetryDelay    = 500 * time.Millisecond
+	DefaultMaxRetryDelay = 30 * time.Second
+)
+
+// RetryBudget is a total time which retries of sends may take. Share one
+// budget between sends of a batch, e.g. by WithRetryBudget in opts of
+// SendChunked, to limit the worst-case batch duration. When the budget is
+// spent, sends are not repeated and fail with an error of their last request.
+//
+// A RetryBudget is safe for concurrent use.
+type RetryBudget struct {
+	mu   sync.Mutex
+	left time.Duration
+}
+
+// NewRetryBudget returns a RetryBudget of d.
+func NewRetryBudget(d time.Duration) *RetryBudget {
+	return &RetryBudget{left: d}
+}
+
+// Remaining returns the time left for retries.
+func (b *RetryBudget) Remaining() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.left
+}
+
+// allows tells whether a retry after delay fits into the budget.
+func (b *RetryBudget) allows(delay time.Duration) bool {
+	return b.Remaining() > delay
+}
+
+// spend takes d from the budget.
+func (b *RetryBudget) spend(d time.Duration) {
+	b.mu.Lock()
+	b.left -= d
+	b.mu.Unlock()
+}
+
+// WithRetryBudget limits a time of retries of a send by b. Both the pause
+// before a retry and its request are taken from b. See Config.MaxRetries.
+func WithRetryBudget(b *RetryBudget) Opt {
+	return func(m *message) { m.RetryBudget = b }
+}
+
+// retry calls do and repeats it after retriable errors up to c.maxRetries
+// times while b allows. b may be nil. idempotent tells that a repeated
+// request cannot send a message twice, see retriable.
+func (c *Client) retry(ctx context.Context, b *RetryBudget, idempotent bool, do func() (*Result, error)) (*Result, error) {
+	r, err := do()
+	attempts := 1
+	for ; err != nil && attempts <= c.maxRetries && retriable(err, idempotent); attempts++ {
+		delay := c.backoff(attempts, err)
+		if ctx.Err() != nil || (b != nil && !b.allows(delay)) {
+			break
+		}
+		start := time.Now()
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		r, err = do()
+		if b != nil {
+			b.spend(time.Since(start))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Meta.Attempts = attempts
+	return r, nil
+}
+
+// retriable tells whether a send which failed with err may be repeated.
+//
+// A send is repeated after errors which prove API did not process it: 429
+// and 503 statuses, temporary API errors and failed connections. Network
+// errors, e.g. timeouts and connection resets, and other temporary statuses
+// may come after a message is sent, so they are repeated only when a request
+// is idempotent, i.e. a message has an id of WithDerivedID which API does not
+// send twice.
+func retriable(err error, idempotent bool) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Temporary()
+	}
+	var h *HTTPError
+	if errors.As(err, &h) && (h.StatusCode == http.StatusTooManyRequests || h.StatusCode == http.StatusServiceUnavailable) {
+		return true
+	}
+	var op *net.OpError
+	if errors.As(err, &op) && op.Op == "dial" {
+		return true
+	}
+	if !idempotent {
+		return false
+	}
+	var ne net.Error
+	return errors.As(err, &ne) || isTemporary(err)
+}
+
+// backoff returns a pause before retry n after err. A pause doubles with each
+// retry up to c.maxDelay, Retry-After of err is used when it is longer.
+func (c *Client) backoff(n int, err error) time.Duration {
+	d := c.retryDelay
+	for i := 1; i < n && d < c.maxDelay; i++ {
+		d *= 2
+	}
+	if d > c.maxDelay {
+		d = c.maxDelay
+	}
+	var e *HTTPError
+	if errors.As(err, &e) && e.RetryAfter > d {
+		d = e.RetryAfter
+	}
+	return d
+}
+
+// sleep pauses for d unless ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
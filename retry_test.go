@@ -0,0 +1,217 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first n requests with 503 status.
+func flakyServer(n int32, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= n {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+}
+
+func TestClient_Send_retry(t *testing.T) {
+	var calls int32
+	ts := flakyServer(2, &calls)
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", MaxRetries: 2, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Send("test", somePhone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Meta.Attempts != 3 || calls != 3 {
+		t.Errorf("want 3 attempts, got %d of %d calls", r.Meta.Attempts, calls)
+	}
+}
+
+func TestClient_Send_retryLimit(t *testing.T) {
+	var calls int32
+	ts := flakyServer(10, &calls)
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", MaxRetries: 1, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Send("test", somePhone)
+	var e *HTTPError
+	if !errors.As(err, &e) {
+		t.Fatalf("want *HTTPError, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("want 2 calls, got %d", calls)
+	}
+}
+
+func TestWithRetryBudget(t *testing.T) {
+	var calls int32
+	ts := flakyServer(1000, &calls)
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", MaxRetries: 100, RetryDelay: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewRetryBudget(20 * time.Millisecond)
+	phones := []string{"1", "2", "3", "4"}
+
+	start := time.Now()
+	a := c.SendChunked(context.Background(), "test", phones, 1, WithRetryBudget(b))
+	if a.Failed() != len(phones) {
+		t.Errorf("want %d failed, got %d", len(phones), a.Failed())
+	}
+	// Each retry takes 5ms at least, so 20ms allow 4 retries of all sends.
+	if max := int32(len(phones) + 4); calls > max {
+		t.Errorf("want %d calls at most, got %d", max, calls)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("budget is not honored: %v", d)
+	}
+	if b.Remaining() > 5*time.Millisecond {
+		t.Errorf("budget is not spent: %v left", b.Remaining())
+	}
+}
+
+func TestWithRetryBudget_concurrent(t *testing.T) {
+	b := NewRetryBudget(time.Second)
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func() {
+			b.spend(time.Millisecond)
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+	if want := time.Second - 10*time.Millisecond; b.Remaining() != want {
+		t.Errorf("want %v, got %v", want, b.Remaining())
+	}
+}
+
+var ClientBackoffTests = []struct {
+	N     int
+	Err   error
+	Delay time.Duration
+}{
+	{1, &HTTPError{StatusCode: http.StatusServiceUnavailable}, time.Second},
+	{2, &HTTPError{StatusCode: http.StatusServiceUnavailable}, 2 * time.Second},
+	{3, &Error{Code: CodeTooManyRequests}, 4 * time.Second},
+	{10, &Error{Code: CodeTooManyRequests}, 5 * time.Second},
+	{1, &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}, 3 * time.Second},
+	{2, &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second}, 2 * time.Second},
+}
+
+func TestClient_backoff(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass", RetryDelay: time.Second, MaxRetryDelay: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range ClientBackoffTests {
+		if d := c.backoff(tt.N, tt.Err); d != tt.Delay {
+			t.Errorf("%d, %v: want %v, got %v", tt.N, tt.Err, tt.Delay, d)
+		}
+	}
+}
+
+func TestClient_Send_retryAfter(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", MaxRetries: 1, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("want 2 calls, got %d", calls)
+	}
+}
+
+var RetriableTests = []struct {
+	Err        error
+	Idempotent bool
+	Retriable  bool
+}{
+	{&Error{Code: CodeTooManyRequests}, false, true},
+	{&Error{Code: CodeAuth}, true, false},
+	{&HTTPError{StatusCode: http.StatusServiceUnavailable}, false, true},
+	{&HTTPError{StatusCode: http.StatusTooManyRequests}, false, true},
+	{&HTTPError{StatusCode: http.StatusBadGateway}, false, false},
+	{&HTTPError{StatusCode: http.StatusBadGateway}, true, true},
+	{&HTTPError{StatusCode: http.StatusNotFound}, true, false},
+	{wrapErr(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), false, true},
+	{wrapErr(&net.OpError{Op: "read", Err: syscall.ECONNRESET}), false, false},
+	{wrapErr(&net.OpError{Op: "read", Err: syscall.ECONNRESET}), true, true},
+	{wrapErr(&net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}), false, false},
+	{wrapErr(&net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}), true, true},
+	{ErrNoPhones, true, false},
+}
+
+func TestRetriable(t *testing.T) {
+	for _, tt := range RetriableTests {
+		t.Run(fmt.Sprintf("%v/%v", tt.Err, tt.Idempotent), func(t *testing.T) {
+			if v := retriable(tt.Err, tt.Idempotent); v != tt.Retriable {
+				t.Errorf("want %v, got %v", tt.Retriable, v)
+			}
+		})
+	}
+}
+
+func TestClient_Send_retryIdempotent(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", MaxRetries: 1, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", somePhone); err == nil || calls != 1 {
+		t.Errorf("want an error of 1 call, got %v of %d calls", err, calls)
+	}
+	calls = 0
+	r, err := c.Send("test", somePhone, WithDerivedID(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Meta.Attempts != 2 || calls != 2 {
+		t.Errorf("want 2 attempts, got %d of %d calls", r.Meta.Attempts, calls)
+	}
+}
@@ -0,0 +1,88 @@
+package smsc
+
+import (
+	"context"
+	"strings"
+)
+
+// cheapestSender returns a sender of the cheapest tariff of operator. Empty
+// sender means a default route.
+func cheapestSender(tariffs []Tariff, operator string) string {
+	var best *Tariff
+	for i := range tariffs {
+		t := &tariffs[i]
+		if !strings.EqualFold(t.Operator, operator) {
+			continue
+		}
+		if best == nil || t.Price < best.Price {
+			best = t
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Sender
+}
+
+// routes groups m.Phones by senders of the cheapest routes. Groups keep the
+// order of phones. A group of a default route keeps m.Sender, it is used
+// when an operator of a phone or its tariff is unknown.
+//
+// Operators are looked up as by Config.HLRPrecheck and share its cache and
+// concurrency.
+func (c *Client) routes(ctx context.Context, m *message) ([]*message, error) {
+	tariffs, err := c.cachedTariffs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, errs, err := c.lookupAll(ctx, m.Phones)
+	if err != nil {
+		return nil, err
+	}
+	var groups []*message
+	bySender := make(map[string]*message)
+	for i, phone := range m.Phones {
+		var sender string
+		if errs[i] == nil && results[i].Operator != "" {
+			sender = cheapestSender(tariffs, results[i].Operator)
+		}
+		g, ok := bySender[sender]
+		if !ok {
+			p := *m
+			if sender != "" {
+				p.Sender = sender
+			}
+			p.Phones = nil
+			g = &p
+			bySender[sender] = g
+			groups = append(groups, g)
+		}
+		g.Phones = append(g.Phones, phone)
+	}
+	return groups, nil
+}
+
+// sendRouted sends m by the cheapest routes and merges their Results. When a
+// route fails, Results of the sent ones are returned with a *PartialError.
+func (c *Client) sendRouted(ctx context.Context, m *message) (*Result, error) {
+	groups, err := c.routes(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 1 {
+		return c.post(ctx, groups[0])
+	}
+	rr := make([]*Result, 0, len(groups))
+	for i, g := range groups {
+		r, err := c.post(ctx, g)
+		if err != nil {
+			var unsent []string
+			for _, g := range groups[i:] {
+				unsent = append(unsent, g.Phones...)
+			}
+			return partial(rr, mergeBatches, i, len(groups), unsent, err)
+		}
+		rr = append(rr, r)
+	}
+	return mergeBatches(rr), nil
+}
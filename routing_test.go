@@ -0,0 +1,180 @@
+package smsc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+var CheapestSenderTests = []struct {
+	Operator string
+	Sender   string
+}{
+	{"MTS", "Cheap"},
+	{"mts", "Cheap"},
+	{"Beeline", ""},
+	{"Unknown", ""},
+}
+
+func TestCheapestSender(t *testing.T) {
+	tariffs := []Tariff{
+		{Operator: "MTS", Price: 200},
+		{Operator: "MTS", Price: 150, Sender: "Cheap"},
+		{Operator: "Beeline", Price: 100},
+		{Operator: "Beeline", Price: 180, Sender: "Cheap"},
+	}
+	for _, tt := range CheapestSenderTests {
+		t.Run(tt.Operator, func(t *testing.T) {
+			if s := cheapestSender(tariffs, tt.Operator); s != tt.Sender {
+				t.Errorf("want %q, got %q", tt.Sender, s)
+			}
+		})
+	}
+}
+
+func TestClient_Send_leastCostRouting(t *testing.T) {
+	operators := map[string]string{
+		"+79161234567": "MTS",
+		"+79031234567": "Beeline",
+		"+79261234567": "MTS",
+	}
+	sent := make(map[string][]string)
+	var tariffRequests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tariffs.php":
+			tariffRequests++
+			json.NewEncoder(w).Encode([]Tariff{
+				{Operator: "MTS", Price: 150, Sender: "Cheap"},
+				{Operator: "MTS", Price: 200},
+				{Operator: "Beeline", Price: 100},
+			})
+		case "/info.php":
+			json.NewEncoder(w).Encode(PhoneInfo{Operator: operators[r.PostFormValue("phone")]})
+		default:
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			sender := r.PostForm.Get("sender")
+			sent[sender] = append(sent[sender], r.PostForm["phones"]...)
+			json.NewEncoder(w).Encode(Result{ID: id, Count: len(r.PostForm["phones"])})
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass", LeastCostRouting: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	phones := []string{"+79161234567", "+79031234567", "+79261234567", "+70000000000"}
+	r, err := c.Send("test", phones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Count != len(phones) {
+		t.Errorf("count: want %d, got %d", len(phones), r.Count)
+	}
+	want := map[string][]string{
+		"Cheap": {"+79161234567", "+79261234567"},
+		"":      {"+79031234567", "+70000000000"},
+	}
+	if !reflect.DeepEqual(want, sent) {
+		t.Errorf("want %v, got %v", want, sent)
+	}
+
+	sent = make(map[string][]string)
+	if _, err := c.Send("test", phones, Sender("Mine")); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string][]string{"Mine": phones}; !reflect.DeepEqual(want, sent) {
+		t.Errorf("want %v, got %v", want, sent)
+	}
+
+	if _, err := c.Send("test", phones); err != nil {
+		t.Fatal(err)
+	}
+	if tariffRequests != 1 {
+		t.Errorf("tariff requests: want 1, got %d", tariffRequests)
+	}
+}
+
+func TestClient_Send_leastCostRoutingPartial(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tariffs.php":
+			json.NewEncoder(w).Encode([]Tariff{{Operator: "MTS", Price: 150, Sender: "Cheap"}})
+		case "/info.php":
+			if r.PostFormValue("phone") == "+79161234567" {
+				json.NewEncoder(w).Encode(PhoneInfo{Operator: "MTS"})
+			} else {
+				json.NewEncoder(w).Encode(PhoneInfo{})
+			}
+		default:
+			if r.PostFormValue("sender") == "" {
+				w.Write([]byte(`{"error": "invalid number", "error_code": 7}`))
+				return
+			}
+			json.NewEncoder(w).Encode(Result{ID: id, Count: 1, Phones: []Phone{{Phone: r.PostFormValue("phones")}}})
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass", LeastCostRouting: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Send("test", []string{"+79161234567", "+70000000000"})
+	var perr *PartialError
+	if !errors.As(err, &perr) {
+		t.Fatalf("want *PartialError, got %v", err)
+	}
+	if perr.Sent != 1 || perr.Total != 2 {
+		t.Errorf("want 1 of 2 sent, got %d of %d", perr.Sent, perr.Total)
+	}
+	if want := []string{"+70000000000"}; !reflect.DeepEqual(want, perr.Unsent) {
+		t.Errorf("unsent: want %v, got %v", want, perr.Unsent)
+	}
+	if want := (&Error{Code: 7, Desc: "invalid number"}); !reflect.DeepEqual(perr.Err, want) {
+		t.Errorf("want %v, got %v", want, perr.Err)
+	}
+	if r == nil || r.Count != 1 || len(r.Phones) != 1 || r.Phones[0].Phone != "+79161234567" {
+		t.Errorf("want a Result of sent phone, got %+v", r)
+	}
+}
+
+func TestClient_Send_leastCostRoutingLookups(t *testing.T) {
+	var mu sync.Mutex
+	lookups := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tariffs.php":
+			json.NewEncoder(w).Encode([]Tariff{{Operator: "MTS", Price: 150, Sender: "Cheap"}})
+		case "/info.php":
+			mu.Lock()
+			lookups[r.PostFormValue("phone")]++
+			mu.Unlock()
+			json.NewEncoder(w).Encode(PhoneInfo{Operator: "MTS"})
+		default:
+			json.NewEncoder(w).Encode(Result{ID: id, Count: 1})
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass", LeastCostRouting: true, HLRPrecheck: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	phones := []string{"+79161234567", "+79261234567"}
+	text := Generate(abc+" ", 2*smsLatinChars)
+	if _, err := c.Send(text, phones, WithAutoSplit(smsLatinChars)); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{phones[0]: 1, phones[1]: 1}; !reflect.DeepEqual(want, lookups) {
+		t.Errorf("lookups: want %v, got %v", want, lookups)
+	}
+}
@@ -0,0 +1,81 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrAlreadySent is returned by Reschedule when a message left the queue.
+var ErrAlreadySent = errors.New("smsc: message is already sent")
+
+// codeNotDeleted is a code of an error of status.php when a message cannot be
+// deleted. The code is the same as CodeDate of send.php.
+const codeNotDeleted = CodeDate
+
+// Cancel deletes a message with id to phone. A scheduled message is not sent
+// then.
+func (c *Client) Cancel(ctx context.Context, id int64, phone string) error {
+	v := url.Values{
+		"id":    []string{strconv.FormatInt(id, 10)},
+		"phone": []string{phone},
+		"del":   []string{"1"},
+	}
+	var r json.RawMessage
+	return c.call(ctx, "status.php", v, &r)
+}
+
+// RescheduleError is returned by Reschedule when a message with ID to Phone
+// was cancelled, but a new one failed with Err. The old message is not sent
+// then, so send it again if needed.
+type RescheduleError struct {
+	ID    int64
+	Phone string
+	Err   error
+}
+
+func (e *RescheduleError) Error() string {
+	return fmt.Sprintf("smsc: message %d to %s is cancelled, a new one failed: %v", e.ID, e.Phone, e.Err)
+}
+
+func (e *RescheduleError) Unwrap() error {
+	return e.Err
+}
+
+// Reschedule replaces a scheduled message with id to phone by text sent at t
+// and returns a Result of a new message.
+//
+// API identifies a message by its id and phone, so they are arguments as of
+// Status and Cancel, and id is int64 as Result.ID. A message to several
+// phones is rescheduled for each phone.
+//
+// API cannot update a message, so it is cancelled and a new one is sent.
+// ErrAlreadySent is returned when the message is not queued any more, also
+// when it leaves the queue between the check and the cancel. A
+// *RescheduleError is returned when the new message fails.
+func (c *Client) Reschedule(ctx context.Context, id int64, phone, text string, t time.Time, opts ...Opt) (*Result, error) {
+	s, err := c.Status(ctx, id, phone)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != StatusQueued {
+		return nil, ErrAlreadySent
+	}
+	if err := c.Cancel(ctx, id, phone); err != nil {
+		// API cannot delete a message which is being sent.
+		if e, ok := err.(*Error); ok && e.Code == codeNotDeleted {
+			return nil, ErrAlreadySent
+		}
+		return nil, err
+	}
+	opts = append(opts[:len(opts):len(opts)], WithSendTime(t))
+	r, err := c.SendContext(ctx, text, []string{phone}, opts...)
+	if err != nil {
+		return r, &RescheduleError{ID: id, Phone: phone, Err: err}
+	}
+	return r, nil
+}
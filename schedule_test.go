@@ -0,0 +1,99 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Reschedule(t *testing.T) {
+	status := -1
+	var deleted bool
+	var sendTime string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/status.php" {
+			if s := r.PostFormValue("id"); s != "1000" {
+				t.Errorf("id: want 1000, got %q", s)
+			}
+			if r.PostFormValue("del") == "1" {
+				deleted = true
+				w.Write([]byte(`{}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]int{"status": status})
+			return
+		}
+		sendTime = r.PostFormValue("time")
+		json.NewEncoder(w).Encode(&Result{ID: id + 1, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := c.Reschedule(context.Background(), id, "+79161234567", "new", day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deleted {
+		t.Error("message is not cancelled")
+	}
+	if r.ID != id+1 {
+		t.Errorf("id: want %d, got %d", id+1, r.ID)
+	}
+	if want := day.Format(sendTimeLayout); sendTime != want {
+		t.Errorf("time: want %q, got %q", want, sendTime)
+	}
+
+	status, deleted = 1, false
+	if _, err := c.Reschedule(context.Background(), id, "+79161234567", "new", day); err != ErrAlreadySent {
+		t.Errorf("want %v, got %v", ErrAlreadySent, err)
+	}
+	if deleted {
+		t.Error("sent message is cancelled")
+	}
+}
+
+func TestClient_Reschedule_errors(t *testing.T) {
+	var deleteErr, sendErr bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path != "/status.php":
+			if sendErr {
+				w.Write([]byte(`{"error": "invalid number", "error_code": 7}`))
+				return
+			}
+			json.NewEncoder(w).Encode(&Result{ID: id + 1, Count: 1})
+		case r.PostFormValue("del") != "1":
+			json.NewEncoder(w).Encode(map[string]int{"status": -1})
+		case deleteErr:
+			w.Write([]byte(`{"error": "message is not deleted", "error_code": 5}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	deleteErr = true
+	if _, err := c.Reschedule(ctx, id, "+79161234567", "new", day); err != ErrAlreadySent {
+		t.Errorf("want %v, got %v", ErrAlreadySent, err)
+	}
+
+	deleteErr, sendErr = false, true
+	_, err = c.Reschedule(ctx, id, "+79161234567", "new", day)
+	want := &RescheduleError{ID: id, Phone: "+79161234567", Err: &Error{Code: CodePhone, Desc: "invalid number"}}
+	if !reflect.DeepEqual(want, err) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+}
@@ -0,0 +1,82 @@
+package smsc
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrBadSender = errors.New("smsc: invalid sender")
+
+// SenderFallback selects a sender by recipients country. Some networks reject
+// alphanumeric senders, so Numeric is used for them.
+type SenderFallback struct {
+	Alpha   string
+	Numeric string
+
+	// NumericCountries lists ISO 3166-1 alpha-2 codes of countries which
+	// require a numeric sender.
+	NumericCountries []string
+}
+
+// validate checks both senders.
+func (f *SenderFallback) validate() error {
+	if !isAlphaSender(f.Alpha) || !isNumericSender(f.Numeric) {
+		return ErrBadSender
+	}
+	return nil
+}
+
+// Sender returns a sender for phones. Numeric is returned when any phone is
+// from NumericCountries. Countries are told by the default prefix table.
+func (f *SenderFallback) Sender(phones []string) string {
+	return f.sender(phones, countries)
+}
+
+// sender returns a sender for phones with countries told by t.
+func (f *SenderFallback) sender(phones []string, t PrefixTable) string {
+	for _, p := range phones {
+		code, ok := t.lookup(p)
+		if !ok {
+			continue
+		}
+		for _, c := range f.NumericCountries {
+			if strings.EqualFold(c, code) {
+				return f.Numeric
+			}
+		}
+	}
+	return f.Alpha
+}
+
+// isAlphaSender tells whether s is a valid alphanumeric sender: up to 11
+// latin letters, digits, spaces, dots or dashes with a letter at least.
+func isAlphaSender(s string) bool {
+	if s == "" || len(s) > 11 {
+		return false
+	}
+	letter := false
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			letter = true
+		case r >= '0' && r <= '9', r == ' ', r == '.', r == '-':
+		default:
+			return false
+		}
+	}
+	return letter
+}
+
+// isNumericSender tells whether s is a valid numeric sender: up to 15 digits.
+func isNumericSender(s string) bool {
+	s = strings.TrimPrefix(s, "+")
+	if s == "" || len(s) > 15 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
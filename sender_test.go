@@ -0,0 +1,46 @@
+package smsc
+
+import "testing"
+
+var SenderFallbackValidateTests = []struct {
+	Fallback SenderFallback
+	Err      error
+}{
+	{SenderFallback{Alpha: "Shop", Numeric: "79001234567"}, nil},
+	{SenderFallback{Alpha: "My-Shop.ru", Numeric: "+79001234567"}, nil},
+	{SenderFallback{Alpha: "", Numeric: "79001234567"}, ErrBadSender},
+	{SenderFallback{Alpha: "12345", Numeric: "79001234567"}, ErrBadSender},
+	{SenderFallback{Alpha: "VeryLongShopName", Numeric: "79001234567"}, ErrBadSender},
+	{SenderFallback{Alpha: "Магазин", Numeric: "79001234567"}, ErrBadSender},
+	{SenderFallback{Alpha: "Shop", Numeric: ""}, ErrBadSender},
+	{SenderFallback{Alpha: "Shop", Numeric: "Shop"}, ErrBadSender},
+	{SenderFallback{Alpha: "Shop", Numeric: "1234567890123456"}, ErrBadSender},
+}
+
+func TestSenderFallback_validate(t *testing.T) {
+	for _, tt := range SenderFallbackValidateTests {
+		t.Run(tt.Fallback.Alpha+"/"+tt.Fallback.Numeric, func(t *testing.T) {
+			if err := tt.Fallback.validate(); err != tt.Err {
+				t.Errorf("want %v, got %v", tt.Err, err)
+			}
+		})
+	}
+}
+
+var SenderFallbackSenderTests = []struct {
+	Phones []string
+	Sender string
+}{
+	{[]string{"+79161234567"}, "Shop"},
+	{[]string{"+79161234567", "+998901234567"}, "79001234567"},
+	{[]string{"+11234567890"}, "Shop"},
+}
+
+func TestSenderFallback_Sender(t *testing.T) {
+	f := SenderFallback{Alpha: "Shop", Numeric: "79001234567", NumericCountries: []string{"UZ"}}
+	for _, tt := range SenderFallbackSenderTests {
+		if s := f.Sender(tt.Phones); s != tt.Sender {
+			t.Errorf("%v: want %q, got %q", tt.Phones, tt.Sender, s)
+		}
+	}
+}
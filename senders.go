@@ -0,0 +1,58 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+var ErrSenderNotApproved = errors.New("smsc: sender is not approved")
+
+// Senders returns names of senders approved for the account.
+func (c *Client) Senders(ctx context.Context) ([]string, error) {
+	var ss []struct {
+		Sender string `json:"sender"`
+	}
+	if err := c.call(ctx, "senders.php", url.Values{"get": []string{"1"}}, &ss); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(ss))
+	for i, s := range ss {
+		names[i] = s.Sender
+	}
+	return names, nil
+}
+
+// RefreshSenders fetches approved senders again for
+// Config.ValidateSenderLocally, e.g. after a new sender is registered.
+func (c *Client) RefreshSenders(ctx context.Context) error {
+	names, err := c.Senders(ctx)
+	if err != nil {
+		return err
+	}
+	c.cache.mu.Lock()
+	c.cache.senders, c.cache.sendersAt = senderSet(names), c.now()
+	c.cache.mu.Unlock()
+	return nil
+}
+
+// senderSet returns a set of names.
+func senderSet(names []string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+// checkSender returns ErrSenderNotApproved unless sender is approved.
+func (c *Client) checkSender(ctx context.Context, sender string) error {
+	names, err := c.cachedSenders(ctx)
+	if err != nil {
+		return err
+	}
+	if !names[sender] {
+		return ErrSenderNotApproved
+	}
+	return nil
+}
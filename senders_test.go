@@ -0,0 +1,79 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Senders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/senders.php" {
+			t.Errorf("path: want %q, got %q", "/senders.php", r.URL.Path)
+		}
+		if s := r.PostFormValue("get"); s != "1" {
+			t.Errorf("get: want 1, got %q", s)
+		}
+		w.Write([]byte(`[{"sender": "Shop"}, {"sender": "Bank"}]`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	senders, err := c.Senders(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Shop", "Bank"}; !reflect.DeepEqual(want, senders) {
+		t.Errorf("want %v, got %v", want, senders)
+	}
+}
+
+func TestClient_Send_validateSenderLocally(t *testing.T) {
+	approved := `[{"sender": "Shop"}]`
+	var fetches, sends int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/senders.php" {
+			fetches++
+			w.Write([]byte(approved))
+			return
+		}
+		sends++
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass", ValidateSenderLocally: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Send("test", somePhone, Sender("Shop")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", somePhone, Sender("Shpo")); err != ErrSenderNotApproved {
+		t.Errorf("want %v, got %v", ErrSenderNotApproved, err)
+	}
+	if _, err := c.Send("test", somePhone); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", somePhone, Sender("Shpo"), WithForceSend()); err != nil {
+		t.Errorf("force: %v", err)
+	}
+	if fetches != 1 || sends != 3 {
+		t.Errorf("want 1 fetch and 3 sends, got %d and %d", fetches, sends)
+	}
+
+	approved = `[{"sender": "Shop"}, {"sender": "Shpo"}]`
+	if err := c.RefreshSenders(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Send("test", somePhone, Sender("Shpo")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrBadAutoSplit = errors.New("smsc: too small auto split length")
+
+// autoSplitMin is a minimum length of WithAutoSplit messages. It leaves room
+// for text after a part indicator.
+const autoSplitMin = 20
+
+// WithAutoSplit sends a text longer than max characters as separate messages
+// with "(1/3) " part indicators instead of a single concatenated SMS. The text
+// is split on word boundaries and indicators are counted in max. Messages are
+// sent in order and Result.Parts holds their Results.
+func WithAutoSplit(max int) Opt {
+	if max < autoSplitMin {
+		panic(ErrBadAutoSplit)
+	}
+	return func(m *message) { m.AutoSplit = max }
+}
+
+// splitText splits text into messages of up to max characters with part
+// indicators. A text which fits max or has no words is not changed, so it is
+// sent and checked by API as is.
+func splitText(text string, max int) []string {
+	if utf8.RuneCountInString(text) <= max || strings.TrimSpace(text) == "" {
+		return []string{text}
+	}
+	// An indicator width depends on a number of parts, so repeat until the
+	// number fits the width.
+	n := 1
+	for {
+		parts := splitWords(text, max-len(partIndicator(n, n)))
+		if len(parts) <= n {
+			for i := range parts {
+				parts[i] = partIndicator(i+1, len(parts)) + parts[i]
+			}
+			return parts
+		}
+		n = len(parts)
+	}
+}
+
+func partIndicator(i, n int) string {
+	return fmt.Sprintf("(%d/%d) ", i, n)
+}
+
+// splitWords splits text into chunks of up to max characters on spaces.
+// Words longer than max are split too.
+func splitWords(text string, max int) []string {
+	var parts []string
+	var cur []rune
+	for _, w := range strings.Fields(text) {
+		word := []rune(w)
+		for len(word) > max {
+			if len(cur) > 0 {
+				parts, cur = append(parts, string(cur)), nil
+			}
+			parts, word = append(parts, string(word[:max])), word[max:]
+		}
+		switch {
+		case len(cur) == 0:
+			cur = word
+		case len(cur)+1+len(word) <= max:
+			cur = append(append(cur, ' '), word...)
+		default:
+			parts, cur = append(parts, string(cur)), word
+		}
+	}
+	if len(cur) > 0 {
+		parts = append(parts, string(cur))
+	}
+	return parts
+}
+
+// sendSplit sends m as separate messages of m.AutoSplit characters. When a
+// message fails, Results of the sent ones are returned with a *PartialError.
+func (c *Client) sendSplit(ctx context.Context, m *message, routed bool) (*Result, error) {
+	texts := splitText(m.Text, m.AutoSplit)
+	rr := make([]*Result, 0, len(texts))
+	for i, text := range texts {
+		p := *m
+		p.Text = text
+		p.AutoSplit = 0
+		r, err := c.dispatch(ctx, &p, routed)
+		if err != nil {
+			if r != nil { // some batches of p were sent
+				rr = append(rr, r)
+			}
+			// Phones which got a previous message are not unsent.
+			var unsent []string
+			if i == 0 {
+				unsent = unsentPhones(err, p.Phones)
+			}
+			return partial(rr, mergeResults, i, len(texts), unsent, err)
+		}
+		rr = append(rr, r)
+	}
+	return mergeResults(rr), nil
+}
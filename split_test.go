@@ -0,0 +1,126 @@
+package smsc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var SplitTextTests = []struct {
+	Text  string
+	Max   int
+	Parts []string
+}{
+	{"short text", 20, []string{"short text"}},
+	{
+		"Your order 1234 is ready, pick it up today",
+		26,
+		[]string{"(1/3) Your order 1234 is", "(2/3) ready, pick it up", "(3/3) today"},
+	},
+	{
+		"Ваш заказ готов, заберите его сегодня",
+		24,
+		[]string{"(1/3) Ваш заказ готов,", "(2/3) заберите его", "(3/3) сегодня"},
+	},
+	{
+		"see https://example.com/very/long/link",
+		20,
+		[]string{"(1/4) see", "(2/4) https://exampl", "(3/4) e.com/very/lon", "(4/4) g/link"},
+	},
+}
+
+func TestSplitText(t *testing.T) {
+	for _, tt := range SplitTextTests {
+		t.Run(tt.Text, func(t *testing.T) {
+			parts := splitText(tt.Text, tt.Max)
+			for _, p := range parts {
+				if n := utf8.RuneCountInString(p); n > tt.Max {
+					t.Errorf("%q: want %d chars at most, got %d", p, tt.Max, n)
+				}
+			}
+			if !reflect.DeepEqual(tt.Parts, parts) {
+				t.Errorf("want %q, got %q", tt.Parts, parts)
+			}
+		})
+	}
+}
+
+func TestWithAutoSplit_panics(t *testing.T) {
+	defer func() {
+		if r := recover(); r != ErrBadAutoSplit {
+			t.Errorf("want %v, got %v", ErrBadAutoSplit, r)
+		}
+	}()
+	WithAutoSplit(autoSplitMin - 1)
+}
+
+func TestClient_Send_autoSplit(t *testing.T) {
+	var texts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		texts = append(texts, r.PostFormValue("mes"))
+		json.NewEncoder(w).Encode(&Result{ID: id + int64(len(texts)), Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := Generate(abc+" ", smsMaxSize+1)
+	r, err := c.Send("test "+text, somePhone, WithAutoSplit(smsLatinChars))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) != len(r.Parts) || r.Count != len(texts) {
+		t.Errorf("want %d parts, got %d of count %d", len(texts), len(r.Parts), r.Count)
+	}
+	if r.ID != id+1 {
+		t.Errorf("id: want %d, got %d", id+1, r.ID)
+	}
+	for i, s := range texts {
+		if want := partIndicator(i+1, len(texts)); !strings.HasPrefix(s, want) {
+			t.Errorf("%d: want prefix %q, got %q", i, want, s)
+		}
+	}
+}
+
+func TestClient_Send_autoSplitPartial(t *testing.T) {
+	var n int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n++; n > 1 {
+			w.Write([]byte(`{"error": "invalid number", "error_code": 7}`))
+			return
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := Generate(abc+" ", 2*smsLatinChars)
+	r, err := c.Send(text, somePhone, WithAutoSplit(smsLatinChars))
+	var perr *PartialError
+	if !errors.As(err, &perr) {
+		t.Fatalf("want *PartialError, got %v", err)
+	}
+	if perr.Sent != 1 || perr.Total < 2 {
+		t.Errorf("want 1 sent of 2 or more, got %d of %d", perr.Sent, perr.Total)
+	}
+	if r == nil || r.ID != id || len(r.Parts) != 1 {
+		t.Errorf("want a Result of the sent message, got %v", r)
+	}
+}
+
+func TestSplitText_blank(t *testing.T) {
+	text := strings.Repeat(" ", 2*autoSplitMin)
+	if parts := splitText(text, autoSplitMin); !reflect.DeepEqual([]string{text}, parts) {
+		t.Errorf("want %q, got %q", []string{text}, parts)
+	}
+}
@@ -0,0 +1,171 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status is a delivery state of a message.
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusQueued
+	StatusEnRoute
+	StatusDelivered
+	StatusExpired
+	StatusUndeliverable
+	StatusRejected
+)
+
+var statusNames = map[Status]string{
+	StatusUnknown:       "unknown",
+	StatusQueued:        "queued",
+	StatusEnRoute:       "en route",
+	StatusDelivered:     "delivered",
+	StatusExpired:       "expired",
+	StatusUndeliverable: "undeliverable",
+	StatusRejected:      "rejected",
+}
+
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("status(%d)", int(s))
+}
+
+// statusCodes maps API status codes to states.
+var statusCodes = map[int]Status{
+	-3: StatusUnknown,       // message not found
+	-1: StatusQueued,        // waiting to be sent
+	0:  StatusEnRoute,       // passed to operator
+	1:  StatusDelivered,     // delivered
+	2:  StatusDelivered,     // read
+	3:  StatusExpired,       // expired
+	4:  StatusDelivered,     // link is clicked
+	20: StatusUndeliverable, // impossible to deliver
+	22: StatusRejected,      // invalid phone
+	23: StatusRejected,      // forbidden
+	24: StatusRejected,      // not enough money
+	25: StatusUndeliverable, // unavailable phone
+}
+
+// ParseStatus returns a Status of API status code, e.g. Callback.Status.
+// Unknown codes are StatusUnknown.
+func ParseStatus(code int) Status {
+	return statusCodes[code]
+}
+
+// Final tells whether s will not change any more.
+func (s Status) Final() bool {
+	switch s {
+	case StatusDelivered, StatusExpired, StatusUndeliverable, StatusRejected:
+		return true
+	}
+	return false
+}
+
+// StatusResult is a delivery status of a message to a phone.
+type StatusResult struct {
+	ID        int64     `json:"id"`
+	Phone     string    `json:"phone"`
+	Code      int       `json:"status"` // of API, see Status
+	Status    Status    `json:"-"`
+	Text      string    `json:"status_name"`
+	Operator  string    `json:"operator"`
+	Cost      Money     `json:"cost"`
+	ErrorCode int       `json:"err"`
+	SentAt    time.Time `json:"-"`
+	ChangedAt time.Time `json:"-"` // of the last status change
+
+	// DeliveredAt is ChangedAt of a delivered message and zero otherwise.
+	DeliveredAt time.Time `json:"-"`
+}
+
+// statusJSON is a JSON form of StatusResult.
+type statusJSON struct {
+	statusFields
+	SendTimestamp int64 `json:"send_timestamp"`
+	LastTimestamp int64 `json:"last_timestamp"`
+}
+
+// statusFields has StatusResult fields without UnmarshalJSON method.
+type statusFields StatusResult
+
+func (r *StatusResult) UnmarshalJSON(b []byte) error {
+	var aux statusJSON
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*r = aux.result()
+	return nil
+}
+
+// result returns a StatusResult of aux. Client methods parse statusJSON and
+// convert it, so Config.StrictParsing applies to its fields.
+func (aux *statusJSON) result() StatusResult {
+	r := StatusResult(aux.statusFields)
+	r.Status = ParseStatus(r.Code)
+	if aux.SendTimestamp != 0 {
+		r.SentAt = time.Unix(aux.SendTimestamp, 0)
+	}
+	if aux.LastTimestamp != 0 {
+		r.ChangedAt = time.Unix(aux.LastTimestamp, 0)
+	}
+	if r.Status == StatusDelivered {
+		r.DeliveredAt = r.ChangedAt
+	}
+	return r
+}
+
+// Status returns a delivery status of a message with id to phone.
+func (c *Client) Status(ctx context.Context, id int64, phone string) (*StatusResult, error) {
+	v := url.Values{
+		"id":    []string{strconv.FormatInt(id, 10)},
+		"phone": []string{phone},
+		"all":   []string{"2"},
+	}
+	var aux statusJSON
+	if err := c.call(ctx, "status.php", v, &aux); err != nil {
+		return nil, err
+	}
+	r := aux.result()
+	if r.ID == 0 {
+		r.ID = id
+	}
+	if r.Phone == "" {
+		r.Phone = phone
+	}
+	return &r, nil
+}
+
+// Statuses returns delivery statuses of messages tt by a single request.
+// Results are in order of API, use their ID and Phone to match them.
+func (c *Client) Statuses(ctx context.Context, tt []Tracked) ([]StatusResult, error) {
+	ids := make([]string, len(tt))
+	phones := make([]string, len(tt))
+	for i, t := range tt {
+		ids[i] = strconv.FormatInt(t.ID, 10)
+		phones[i] = t.Phone
+	}
+	v := url.Values{
+		"id":    []string{strings.Join(ids, ",")},
+		"phone": []string{strings.Join(phones, ",")},
+		"all":   []string{"2"},
+	}
+	var aux []statusJSON
+	if err := c.call(ctx, "status.php", v, &aux); err != nil {
+		return nil, err
+	}
+	rr := make([]StatusResult, len(aux))
+	for i := range aux {
+		rr[i] = aux[i].result()
+	}
+	return rr, nil
+}
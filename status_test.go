@@ -0,0 +1,148 @@
+package smsc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var ParseStatusTests = []struct {
+	Code   int
+	Status Status
+}{
+	{-3, StatusUnknown},
+	{-1, StatusQueued},
+	{0, StatusEnRoute},
+	{1, StatusDelivered},
+	{2, StatusDelivered},
+	{3, StatusExpired},
+	{4, StatusDelivered},
+	{20, StatusUndeliverable},
+	{22, StatusRejected},
+	{23, StatusRejected},
+	{24, StatusRejected},
+	{25, StatusUndeliverable},
+	{100, StatusUnknown},
+}
+
+func TestParseStatus(t *testing.T) {
+	for _, tt := range ParseStatusTests {
+		t.Run(fmt.Sprint(tt.Code), func(t *testing.T) {
+			if s := ParseStatus(tt.Code); s != tt.Status {
+				t.Errorf("want %v, got %v", tt.Status, s)
+			}
+		})
+	}
+}
+
+func TestStatus_String(t *testing.T) {
+	for s := StatusUnknown; s <= StatusRejected; s++ {
+		if name := s.String(); name != statusNames[s] || name == "" {
+			t.Errorf("%d: got %q", int(s), name)
+		}
+	}
+	if s := Status(100).String(); s != "status(100)" {
+		t.Errorf("want %q, got %q", "status(100)", s)
+	}
+}
+
+func TestClient_Status(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status.php" {
+			t.Errorf("path: want %q, got %q", "/status.php", r.URL.Path)
+		}
+		if r.PostFormValue("id") != "1000" || r.PostFormValue("phone") != "79161234567" || r.PostFormValue("all") != "2" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		w.Write([]byte(`{"status": 1, "status_name": "Delivered", "err": 0,
+			"send_timestamp": 1577836800, "last_timestamp": 1577836860,
+			"phone": "79161234567", "cost": "1.40", "operator": "MTS"}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Status(context.Background(), 1000, "79161234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &StatusResult{
+		ID:          1000,
+		Phone:       "79161234567",
+		Code:        1,
+		Status:      StatusDelivered,
+		Text:        "Delivered",
+		Operator:    "MTS",
+		Cost:        140,
+		SentAt:      time.Unix(1577836800, 0),
+		ChangedAt:   time.Unix(1577836860, 0),
+		DeliveredAt: time.Unix(1577836860, 0),
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("want %+v, got %+v", want, r)
+	}
+}
+
+func TestClient_Statuses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("id") != "1,2" || r.PostFormValue("phone") != "71,72" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		w.Write([]byte(`[
+			{"id": 1, "phone": "71", "status": -1, "last_timestamp": 1577836800},
+			{"id": 2, "phone": "72", "status": 22, "err": 1}
+		]`))
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr, err := c.Statuses(context.Background(), []Tracked{{1, "71"}, {2, "72"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rr) != 2 {
+		t.Fatalf("want 2 results, got %d", len(rr))
+	}
+	if rr[0].Status != StatusQueued || !rr[0].DeliveredAt.IsZero() || rr[0].ChangedAt.IsZero() {
+		t.Errorf("unexpected %+v", rr[0])
+	}
+	if rr[1].Status != StatusRejected || rr[1].ErrorCode != 1 {
+		t.Errorf("unexpected %+v", rr[1])
+	}
+}
+
+func TestClient_Status_strict(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.PostFormValue("id"), ",") {
+			w.Write([]byte(`[{"id": 1, "status": 1, "bogus_field": 5}]`))
+			return
+		}
+		w.Write([]byte(`{"status": 1, "bogus_field": 5}`))
+	}))
+	defer ts.Close()
+
+	for _, strict := range []bool{false, true} {
+		c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", StrictParsing: strict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.Status(context.Background(), 1, "71")
+		if _, ok := err.(*ParseError); ok != strict {
+			t.Errorf("strict %v: Status: want a *ParseError %v, got %v", strict, strict, err)
+		}
+		_, err = c.Statuses(context.Background(), []Tracked{{1, "71"}, {2, "72"}})
+		if _, ok := err.(*ParseError); ok != strict {
+			t.Errorf("strict %v: Statuses: want a *ParseError %v, got %v", strict, strict, err)
+		}
+	}
+}
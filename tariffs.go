@@ -0,0 +1,35 @@
+package smsc
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Tariff is a price of SMS for an operator.
+type Tariff struct {
+	Country  string `json:"country"`
+	Operator string `json:"operator"`
+	Price    Money  `json:"cost"`
+
+	// Sender is a sender the price applies to. Empty Sender is a default
+	// route.
+	Sender string `json:"sender"`
+
+	// FetchedAt is a time when the price list was received. Tariffs change
+	// rarely, so it may be used to expire a cached list.
+	FetchedAt time.Time `json:"-"`
+}
+
+// Tariffs returns the price list of the account.
+func (c *Client) Tariffs(ctx context.Context) ([]Tariff, error) {
+	var tt []Tariff
+	if err := c.call(ctx, "tariffs.php", url.Values{}, &tt); err != nil {
+		return nil, err
+	}
+	now := c.now()
+	for i := range tt {
+		tt[i].FetchedAt = now
+	}
+	return tt, nil
+}
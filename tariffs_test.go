@@ -0,0 +1,69 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var ClientTariffsTests = []struct {
+	Value   interface{}
+	Tariffs []Tariff
+	Err     error
+}{
+	{
+		Value: json.RawMessage(`[
+			{"country": "Russia", "operator": "MTS", "cost": "1.5"},
+			{"country": "Russia", "operator": "Beeline", "cost": "1,6"}
+		]`),
+		Tariffs: []Tariff{
+			{Country: "Russia", Operator: "MTS", Price: 150},
+			{Country: "Russia", Operator: "Beeline", Price: 160},
+		},
+	},
+	{
+		Value: &Error{Code: 2, Desc: "authorise error"},
+		Err:   &Error{Code: 2, Desc: "authorise error"},
+	},
+}
+
+func TestClient_Tariffs(t *testing.T) {
+	for _, tt := range ClientTariffsTests {
+		t.Run(fmt.Sprintf("%v", tt.Err), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/tariffs.php" {
+					t.Errorf("path: want %q, got %q", "/tariffs.php", r.URL.Path)
+				}
+				if s := r.PostFormValue("login"); s != "test" {
+					t.Errorf("login: want %q, got %q", "test", s)
+				}
+				json.NewEncoder(w).Encode(tt.Value)
+			}))
+			defer ts.Close()
+
+			c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tariffs, err := c.Tariffs(context.Background())
+			if !reflect.DeepEqual(tt.Err, err) {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+			for i := range tariffs {
+				if tariffs[i].FetchedAt.IsZero() {
+					t.Errorf("%d: zero FetchedAt", i)
+				}
+				tariffs[i].FetchedAt = time.Time{}
+			}
+			if !reflect.DeepEqual(tt.Tariffs, tariffs) {
+				t.Errorf("tariffs: want %v, got %v", tt.Tariffs, tariffs)
+			}
+		})
+	}
+}
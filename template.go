@@ -0,0 +1,76 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrBadTemplate = errors.New("smsc: invalid template")
+
+// MissingPlaceholderError is returned when a value of a placeholder is not
+// provided to Template.Send.
+type MissingPlaceholderError struct {
+	Name string
+}
+
+func (e *MissingPlaceholderError) Error() string {
+	return fmt.Sprintf("smsc: missing value of placeholder {%s}", e.Name)
+}
+
+// Template is a message text with named placeholders, e.g. "Code {code}".
+// Placeholders are substituted locally, API templates are not used.
+type Template struct {
+	c      *Client
+	chunks []string // literals at even and placeholder names at odd indexes
+}
+
+// Template parses s into a Template. A placeholder name is non-empty and has
+// no braces, so "{" and "}" cannot be used in literal text.
+func (c *Client) Template(s string) (*Template, error) {
+	t := &Template{c: c}
+	for {
+		i := strings.IndexAny(s, "{}")
+		if i < 0 {
+			t.chunks = append(t.chunks, s)
+			return t, nil
+		}
+		if s[i] == '}' {
+			return nil, ErrBadTemplate
+		}
+		j := strings.IndexAny(s[i+1:], "{}")
+		if j <= 0 || s[i+1+j] != '}' {
+			return nil, ErrBadTemplate
+		}
+		t.chunks = append(t.chunks, s[:i], s[i+1:i+1+j])
+		s = s[i+1+j+1:]
+	}
+}
+
+// Execute returns a text with placeholders substituted with values. A
+// *MissingPlaceholderError is returned when a value is not provided.
+func (t *Template) Execute(values map[string]string) (string, error) {
+	var b strings.Builder
+	for i, s := range t.chunks {
+		if i%2 == 0 {
+			b.WriteString(s)
+			continue
+		}
+		v, ok := values[s]
+		if !ok {
+			return "", &MissingPlaceholderError{Name: s}
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
+
+// Send substitutes values and sends a text to phones.
+func (t *Template) Send(ctx context.Context, phones []string, values map[string]string, opts ...Opt) (*Result, error) {
+	text, err := t.Execute(values)
+	if err != nil {
+		return nil, err
+	}
+	return t.c.SendContext(ctx, text, phones, opts...)
+}
@@ -0,0 +1,74 @@
+package smsc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var TemplateTests = []struct {
+	Template string
+	Values   map[string]string
+	Text     string
+	Err      error
+}{
+	{"Hello", nil, "Hello", nil},
+	{"Hello {name}, code {code}", map[string]string{"name": "Bob", "code": "1234"}, "Hello Bob, code 1234", nil},
+	{"{code}{code}", map[string]string{"code": "12"}, "1212", nil},
+	{"Hello {name}", map[string]string{"name": "{code}"}, "Hello {code}", nil},
+	{"Hello {name}, code {code}", map[string]string{"name": "Bob"}, "", &MissingPlaceholderError{"code"}},
+	{"Hello {}", nil, "", ErrBadTemplate},
+	{"Hello {name", nil, "", ErrBadTemplate},
+	{"Hello name}", nil, "", ErrBadTemplate},
+	{"Hello {{name}}", nil, "", ErrBadTemplate},
+}
+
+func TestClient_Template(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range TemplateTests {
+		t.Run(tt.Template, func(t *testing.T) {
+			tmpl, err := c.Template(tt.Template)
+			if err == nil {
+				var text string
+				text, err = tmpl.Execute(tt.Values)
+				if text != tt.Text {
+					t.Errorf("want %q, got %q", tt.Text, text)
+				}
+			}
+			if !reflect.DeepEqual(tt.Err, err) {
+				t.Errorf("error: want %v, got %v", tt.Err, err)
+			}
+		})
+	}
+}
+
+func TestTemplate_Send(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := r.PostFormValue("mes"); s != "Code 1234" {
+			t.Errorf("mes: want %q, got %q", "Code 1234", s)
+		}
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := c.Template("Code {code}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmpl.Send(context.Background(), somePhone, map[string]string{"code": "1234"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmpl.Send(context.Background(), somePhone, nil); err == nil {
+		t.Error("want error, got nil")
+	}
+}
@@ -0,0 +1,79 @@
+package smsc
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+var (
+	ErrConcatRefBin   = errors.New("smsc: concatenation reference requires binary message")
+	ErrConcatRefShort = errors.New("smsc: concatenation reference requires multipart payload")
+	ErrConcatRefLong  = errors.New("smsc: too many parts of binary message")
+	ErrBadHex         = errors.New("smsc: invalid hex text")
+)
+
+const (
+	binSize     = 140 // bytes of a single binary SMS
+	udhSize     = 6   // bytes of a concatenation UDH
+	binPartSize = binSize - udhSize
+	binMaxParts = 255
+)
+
+// payload returns binary data of m.Text.
+func (m *message) payload() ([]byte, error) {
+	if m.Bin != BinHex {
+		return []byte(m.Text), nil
+	}
+	b, err := hex.DecodeString(m.Text)
+	if err != nil {
+		return nil, ErrBadHex
+	}
+	return b, nil
+}
+
+// validateConcat checks m can be split with m.ConcatRef.
+func (m *message) validateConcat() error {
+	if m.ConcatRef == nil {
+		return nil
+	}
+	if m.Bin == 0 {
+		return ErrConcatRefBin
+	}
+	b, err := m.payload()
+	if err != nil {
+		return err
+	}
+	if len(b) <= binSize {
+		return ErrConcatRefShort
+	}
+	if (len(b)+binPartSize-1)/binPartSize > binMaxParts {
+		return ErrConcatRefLong
+	}
+	return nil
+}
+
+// Parts splits a binary message into messages with a concatenation UDH. Each
+// part is a hex message.
+func (m *message) Parts() ([]*message, error) {
+	b, err := m.payload()
+	if err != nil {
+		return nil, err
+	}
+
+	total := (len(b) + binPartSize - 1) / binPartSize
+	parts := make([]*message, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * binPartSize
+		if end > len(b) {
+			end = len(b)
+		}
+		udh := []byte{0x05, 0x00, 0x03, *m.ConcatRef, byte(total), byte(i + 1)}
+
+		p := *m
+		p.Text = hex.EncodeToString(append(udh, b[i*binPartSize:end]...))
+		p.Bin = BinHex
+		p.ConcatRef = nil
+		parts = append(parts, &p)
+	}
+	return parts, nil
+}
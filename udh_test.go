@@ -0,0 +1,187 @@
+package smsc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var ref uint8 = 42
+
+var MessageValidateConcatTests = []struct {
+	Name    string
+	Message message
+	Err     error
+}{
+	{
+		"Binary multipart payload",
+		message{Text: strings.Repeat("ab", binSize+1), Bin: BinHex, ConcatRef: &ref},
+		nil,
+	},
+	{
+		"Not binary",
+		message{Text: strings.Repeat("a", binSize+1), ConcatRef: &ref},
+		ErrConcatRefBin,
+	},
+	{
+		"Single part",
+		message{Text: strings.Repeat("ab", binSize), Bin: BinHex, ConcatRef: &ref},
+		ErrConcatRefShort,
+	},
+	{
+		"Bad hex",
+		message{Text: strings.Repeat("zz", binSize+1), Bin: BinHex, ConcatRef: &ref},
+		ErrBadHex,
+	},
+	{
+		"Too many parts",
+		message{Text: strings.Repeat("a", binPartSize*binMaxParts+1), Bin: Bin, ConcatRef: &ref},
+		ErrConcatRefLong,
+	},
+}
+
+func TestMessage_validateConcat(t *testing.T) {
+	for _, tt := range MessageValidateConcatTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := tt.Message.validateConcat(); err != tt.Err {
+				t.Errorf("want %v, got %v", tt.Err, err)
+			}
+		})
+	}
+}
+
+func TestMessage_Parts(t *testing.T) {
+	m := message{Text: strings.Repeat("a", binPartSize*2+1), Bin: Bin, ConcatRef: &ref}
+	parts, err := m.Parts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("want 3 parts, got %d", len(parts))
+	}
+	for i, p := range parts {
+		b, err := hex.DecodeString(p.Text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		udh := []byte{0x05, 0x00, 0x03, ref, 3, byte(i + 1)}
+		if string(b[:udhSize]) != string(udh) {
+			t.Errorf("%d: want UDH %x, got %x", i, udh, b[:udhSize])
+		}
+		if p.Bin != BinHex || p.ConcatRef != nil {
+			t.Errorf("%d: want hex part without reference", i)
+		}
+	}
+	if b, _ := hex.DecodeString(parts[2].Text); len(b) != udhSize+1 {
+		t.Errorf("last part: want %d bytes, got %d", udhSize+1, len(b))
+	}
+}
+
+func TestClient_Send_concatRef(t *testing.T) {
+	var n int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		if s := r.PostFormValue("bin"); s != formatOpt(BinHex) {
+			t.Errorf("bin: want %q, got %q", formatOpt(BinHex), s)
+		}
+		json.NewEncoder(w).Encode(&Result{ID: n, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := strings.Repeat("ab", binSize+1)
+	r, err := c.SendContext(context.Background(), text, somePhone, With(BinHex), WithConcatRef(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 1 || r.Count != 2 || len(r.Parts) != 2 {
+		t.Errorf("want ID 1, Count 2, 2 parts, got %v with %d parts", r, len(r.Parts))
+	}
+}
+
+func TestClient_Send_concatRefLong(t *testing.T) {
+	var n int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		balance := fmt.Sprintf("%d.00", 100-n)
+		json.NewEncoder(w).Encode(&Result{ID: id, Count: 1, Cost: &cost, Balance: &balance})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass", MinBalance: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.setBalance(10000) // no balance.php request
+
+	text := strings.Repeat("a", 1000)
+	r, err := c.SendContext(context.Background(), text, somePhone, With(Bin), WithConcatRef(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := (len(text) + binPartSize - 1) / binPartSize
+	if n != parts || len(r.Parts) != parts {
+		t.Fatalf("want %d requests and parts, got %d and %d", parts, n, len(r.Parts))
+	}
+	if m, err := r.CostMoney(); err != nil || m != Money(150*parts) {
+		t.Errorf("cost: want %s, got %s (%v)", Money(150*parts), m, err)
+	}
+	want := fmt.Sprintf("%d.00", 100-parts)
+	if r.Balance == nil || *r.Balance != want {
+		t.Errorf("balance: want %s, got %v", want, r.Balance)
+	}
+	if b, _ := c.cachedBalance(context.Background()); b != Money(100*(100-parts)) {
+		t.Errorf("cached balance: want %s, got %s", want, b)
+	}
+}
+
+func TestMergeResults_cost(t *testing.T) {
+	one, bad := "1.00", "x"
+	r := mergeResults([]*Result{{Cost: &one}, {Cost: &one}})
+	if r.Cost == nil || *r.Cost != "2.00" {
+		t.Errorf("want 2.00, got %v", r.Cost)
+	}
+	for _, rr := range [][]*Result{{{Cost: &one}, {}}, {{Cost: &one}, {Cost: &bad}}} {
+		if r := mergeResults(rr); r.Cost != nil {
+			t.Errorf("want no cost, got %s", *r.Cost)
+		}
+	}
+}
+
+func TestClient_Send_concatRefPartial(t *testing.T) {
+	var n int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n++; n > 1 {
+			w.Write([]byte(`{"error": "invalid number", "error_code": 7}`))
+			return
+		}
+		json.NewEncoder(w).Encode(&Result{ID: n, Count: 1})
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL, Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := strings.Repeat("ab", binSize+1)
+	r, err := c.SendContext(context.Background(), text, somePhone, With(BinHex), WithConcatRef(ref))
+	var perr *PartialError
+	if !errors.As(err, &perr) {
+		t.Fatalf("want *PartialError, got %v", err)
+	}
+	if perr.Sent != 1 || perr.Total != 2 {
+		t.Errorf("want 1 of 2 sent, got %d of %d", perr.Sent, perr.Total)
+	}
+	if r == nil || r.ID != 1 || len(r.Parts) != 1 {
+		t.Errorf("want a Result of the sent part, got %v", r)
+	}
+}
@@ -0,0 +1,82 @@
+package smsc
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrBadViberURL    = errors.New("smsc: viber url must be http or https")
+	ErrBadViberButton = errors.New("smsc: invalid viber button caption")
+	ErrViberChannel   = errors.New("smsc: image and button require viber")
+)
+
+// viberButtonMax is a maximum length of a Viber button caption.
+const viberButtonMax = 30
+
+// viberButton is a button of a rich Viber message.
+type viberButton struct {
+	Caption string
+	URL     string
+}
+
+// WithViberImage adds an image loaded from u to a Viber message.
+func WithViberImage(u string) Opt {
+	mustViberURL(u)
+	return func(m *message) { m.ViberImage = u }
+}
+
+// WithViberButton adds a button which opens u to a Viber message. caption
+// must be 1 to 30 characters without "|".
+func WithViberButton(caption, u string) Opt {
+	n := utf8.RuneCountInString(caption)
+	if n == 0 || n > viberButtonMax || strings.Contains(caption, "|") {
+		panic(ErrBadViberButton)
+	}
+	mustViberURL(u)
+	return func(m *message) { m.ViberButton = &viberButton{caption, u} }
+}
+
+// mustViberURL panics with ErrBadViberURL unless s is an absolute http(s) URL.
+func mustViberURL(s string) {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		panic(ErrBadViberURL)
+	}
+}
+
+// validateViber checks that an image and a button are used with Viber only,
+// either as a message kind or as one of WithFallbackChannels.
+func (m *message) validateViber() error {
+	if m.ViberImage == "" && m.ViberButton == nil {
+		return nil
+	}
+	if m.channel() == ChannelViber {
+		return nil
+	}
+	for _, ch := range m.Channels {
+		if ch == ChannelViber {
+			return nil
+		}
+	}
+	return ErrViberChannel
+}
+
+// viberText returns m.Text with the image and the button tags API expects in
+// a rich Viber message.
+func (m *message) viberText() string {
+	if m.ViberImage == "" && m.ViberButton == nil {
+		return m.Text
+	}
+	var b strings.Builder
+	b.WriteString(m.Text)
+	if m.ViberButton != nil {
+		b.WriteString("<button>" + m.ViberButton.Caption + "|" + m.ViberButton.URL + "</button>")
+	}
+	if m.ViberImage != "" {
+		b.WriteString("<img>" + m.ViberImage + "</img>")
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package smsc
+
+import "testing"
+
+func TestWithViberButton_panics(t *testing.T) {
+	tests := []struct {
+		Caption, URL string
+		Err          error
+	}{
+		{"", "https://example.com", ErrBadViberButton},
+		{Generate(abc, viberButtonMax+1), "https://example.com", ErrBadViberButton},
+		{"Buy|now", "https://example.com", ErrBadViberButton},
+		{"Buy", "ftp://example.com", ErrBadViberURL},
+		{"Buy", "/shop", ErrBadViberURL},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != tt.Err {
+					t.Errorf("%q %q: want %v, got %v", tt.Caption, tt.URL, tt.Err, r)
+				}
+			}()
+			WithViberButton(tt.Caption, tt.URL)
+		}()
+	}
+}
+
+func TestMessage_viberText(t *testing.T) {
+	m := message{Text: "Sale", Phones: somePhone}
+	With(Viber, WithViberImage("https://example.com/a.png"), WithViberButton("Buy", "https://example.com"))(&m)
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := "Sale<button>Buy|https://example.com</button><img>https://example.com/a.png</img>"
+	if s := m.Values().Get("mes"); s != want {
+		t.Errorf("want %q, got %q", want, s)
+	}
+}
+
+func TestMessage_Validate_viberChannel(t *testing.T) {
+	m := message{Text: "Sale", Phones: somePhone}
+	WithViberImage("https://example.com/a.png")(&m)
+	if err := m.Validate(); err != ErrViberChannel {
+		t.Errorf("want %v, got %v", ErrViberChannel, err)
+	}
+	WithFallbackChannels(ChannelViber, ChannelSMS)(&m)
+	m.ChannelSenders = map[Channel]string{ChannelViber: "Shop"}
+	if err := m.Validate(); err != nil {
+		t.Errorf("fallback: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package smsc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrBadInterval = errors.New("smsc: watch interval must be positive")
+
+// watchMaxBackoff limits a delay between polls of a message by a number of
+// StatusWatcher intervals.
+const watchMaxBackoff = 32
+
+// Tracked is a message to a phone, e.g. tracked by a StatusWatcher.
+type Tracked struct {
+	ID    int64
+	Phone string
+}
+
+// WatchStore keeps messages tracked by a StatusWatcher, so tracking survives
+// restarts. Implement it to keep messages in a database.
+//
+// Implementations must be safe for concurrent use.
+type WatchStore interface {
+	// Add starts tracking t. A message with a final status is not tracked
+	// again.
+	Add(ctx context.Context, t Tracked) error
+	// Pending returns tracked messages without a final status.
+	Pending(ctx context.Context) ([]Tracked, error)
+	// Finish stores final status s of t.
+	Finish(ctx context.Context, t Tracked, s Status) error
+}
+
+// StatusWatcher polls statuses of tracked messages until they are final, e.g.
+// of messages with a long validity. A message which is not final is polled
+// less often each time, up to watchMaxBackoff intervals.
+type StatusWatcher struct {
+	c        *Client
+	store    WatchStore
+	interval time.Duration
+	fn       func(Tracked, Status)
+
+	mu    sync.Mutex
+	state map[Tracked]watchState
+}
+
+// watchState schedules a next poll of a message.
+type watchState struct {
+	Next  time.Time
+	Delay time.Duration
+}
+
+// NewStatusWatcher returns a StatusWatcher which keeps messages in store and
+// polls them every interval. fn is called once with a final status of each
+// message. It panics with ErrBadInterval when interval is not positive.
+func (c *Client) NewStatusWatcher(store WatchStore, interval time.Duration, fn func(Tracked, Status)) *StatusWatcher {
+	if interval <= 0 {
+		panic(ErrBadInterval)
+	}
+	return &StatusWatcher{
+		c:        c,
+		store:    store,
+		interval: interval,
+		fn:       fn,
+		state:    make(map[Tracked]watchState),
+	}
+}
+
+// Watch starts tracking a message with id to phone, e.g. of Result.ID.
+func (w *StatusWatcher) Watch(ctx context.Context, id int64, phone string) error {
+	return wrapErr(w.store.Add(ctx, Tracked{id, phone}))
+}
+
+// Run polls tracked messages until ctx is done and returns ctx.Err(). Errors
+// of the store are returned at once, errors of API delay a poll of a message.
+func (w *StatusWatcher) Run(ctx context.Context) error {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		if err := w.poll(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// poll checks statuses of pending messages which are due.
+func (w *StatusWatcher) poll(ctx context.Context) error {
+	pending, err := w.store.Pending(ctx)
+	if err != nil {
+		return wrapErr(err)
+	}
+	w.forget(pending)
+	for _, t := range pending {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !w.due(t) {
+			continue
+		}
+		r, err := w.c.Status(ctx, t.ID, t.Phone)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			w.delay(t)
+			continue
+		}
+		s := r.Status
+		if !s.Final() {
+			w.delay(t)
+			continue
+		}
+		if err := w.store.Finish(ctx, t, s); err != nil {
+			return wrapErr(err)
+		}
+		w.mu.Lock()
+		delete(w.state, t)
+		w.mu.Unlock()
+		w.fn(t, s)
+	}
+	return nil
+}
+
+// forget drops schedules of messages which are not pending any more, e.g.
+// finished or removed from the store elsewhere.
+func (w *StatusWatcher) forget(pending []Tracked) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.state) == 0 {
+		return
+	}
+	keep := make(map[Tracked]bool, len(pending))
+	for _, t := range pending {
+		keep[t] = true
+	}
+	for t := range w.state {
+		if !keep[t] {
+			delete(w.state, t)
+		}
+	}
+}
+
+// due tells whether t should be polled now.
+func (w *StatusWatcher) due(t Tracked) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.c.now().Before(w.state[t].Next)
+}
+
+// delay postpones a next poll of t twice as long as the previous one.
+func (w *StatusWatcher) delay(t Tracked) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st := w.state[t]
+	if st.Delay == 0 {
+		st.Delay = w.interval
+	} else if st.Delay < watchMaxBackoff*w.interval {
+		st.Delay *= 2
+	}
+	st.Next = w.c.now().Add(st.Delay)
+	w.state[t] = st
+}
+
+// MemoryWatchStore is a WatchStore which keeps messages in memory.
+type MemoryWatchStore struct {
+	mu    sync.Mutex
+	items map[Tracked]*Status // nil of pending messages
+}
+
+// NewMemoryWatchStore returns an empty MemoryWatchStore.
+func NewMemoryWatchStore() *MemoryWatchStore {
+	return &MemoryWatchStore{items: make(map[Tracked]*Status)}
+}
+
+func (s *MemoryWatchStore) Add(ctx context.Context, t Tracked) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[t]; !ok {
+		s.items[t] = nil
+	}
+	return nil
+}
+
+func (s *MemoryWatchStore) Pending(ctx context.Context) ([]Tracked, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tt []Tracked
+	for t, st := range s.items {
+		if st == nil {
+			tt = append(tt, t)
+		}
+	}
+	return tt, nil
+}
+
+func (s *MemoryWatchStore) Finish(ctx context.Context, t Tracked, st Status) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[t] = &st
+	return nil
+}
+
+// Status returns a final status of t, or false if t is pending or unknown.
+func (s *MemoryWatchStore) Status(t Tracked) (Status, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.items[t]
+	if st == nil {
+		return StatusUnknown, false
+	}
+	return *st, true
+}
@@ -0,0 +1,147 @@
+package smsc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatusWatcher(t *testing.T) {
+	var mu sync.Mutex
+	polls := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := r.PostFormValue("id")
+		polls[id]++
+		switch {
+		case id == "1" && polls[id] < 3:
+			w.Write([]byte(`{"status": -1}`))
+		case id == "1":
+			w.Write([]byte(`{"status": 1}`))
+		case id == "2":
+			w.Write([]byte(`{"status": 20}`))
+		default:
+			w.Write([]byte(`{"error": "server error", "error_code": 9}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(Config{URL: ts.URL + "/send.php", Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemoryWatchStore()
+	final := make(chan Tracked, 10)
+	w := c.NewStatusWatcher(store, time.Millisecond, func(t Tracked, s Status) { final <- t })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, id := range []int64{1, 2, 3} {
+		if err := w.Watch(ctx, id, "79161234567"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	got := map[int64]bool{}
+	for len(got) < 2 {
+		select {
+		case tr := <-final:
+			if got[tr.ID] {
+				t.Errorf("%d is reported twice", tr.ID)
+			}
+			got[tr.ID] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	// A message with a final status is not tracked again.
+	if err := w.Watch(ctx, 2, "79161234567"); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+
+	if s, ok := store.Status(Tracked{1, "79161234567"}); !ok || s != StatusDelivered {
+		t.Errorf("1: want %v, got %v", StatusDelivered, s)
+	}
+	if s, ok := store.Status(Tracked{2, "79161234567"}); !ok || s != StatusUndeliverable {
+		t.Errorf("2: want %v, got %v", StatusUndeliverable, s)
+	}
+	if _, ok := store.Status(Tracked{3, "79161234567"}); ok {
+		t.Error("3: want pending")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if polls["2"] != 1 {
+		t.Errorf("2: want a single poll, got %d", polls["2"])
+	}
+}
+
+func TestStatusWatcher_delay(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return day }
+	w := c.NewStatusWatcher(NewMemoryWatchStore(), time.Minute, nil)
+	tr := Tracked{1, "79161234567"}
+
+	var delays []time.Duration
+	for i := 0; i < 8; i++ {
+		w.delay(tr)
+		delays = append(delays, w.state[tr].Delay)
+	}
+	if delays[0] != time.Minute || delays[1] != 2*time.Minute {
+		t.Errorf("unexpected delays %v", delays)
+	}
+	if max := watchMaxBackoff * time.Minute; delays[7] != max {
+		t.Errorf("want %v at most, got %v", max, delays[7])
+	}
+	if w.due(tr) {
+		t.Error("want a poll to be delayed")
+	}
+}
+
+func TestNewStatusWatcher_panics(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if r := recover(); r != ErrBadInterval {
+			t.Errorf("want %v, got %v", ErrBadInterval, r)
+		}
+	}()
+	c.NewStatusWatcher(NewMemoryWatchStore(), 0, nil)
+}
+
+func TestStatusWatcher_forget(t *testing.T) {
+	c, err := New(Config{Login: "test", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return day }
+	store := NewMemoryWatchStore()
+	w := c.NewStatusWatcher(store, time.Minute, nil)
+	tr := Tracked{1, "79161234567"}
+	w.delay(tr)
+
+	ctx := context.Background()
+	if err := store.Finish(ctx, tr, StatusDelivered); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.state) != 0 {
+		t.Errorf("want no state of a finished message, got %v", w.state)
+	}
+}